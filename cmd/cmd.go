@@ -1,32 +1,45 @@
 package cmd
 
 import (
+	"bufio"
+	"errors"
 	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
-	"sort"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 
 	"mpm/pkg/config"
+	"mpm/pkg/shell"
 	"mpm/pkg/ui"
 )
 
+// store is the config store shared by all commands
+var store *config.Store
+
 // InitApp initializes the command line interface
 func InitApp() {
-	config.InitConfig()
-
 	var rootCmd = &cobra.Command{
 		Use:   "mpm",
 		Short: "My Project Manager - A CLI tool to manage your projects",
 		Long: `My Project Manager (mpm) is a CLI tool that helps you manage your projects
 by storing their locations on the filesystem and providing quick navigation.`,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		// Replaced by our own completion command, which skips loading the config
+		CompletionOptions: cobra.CompletionOptions{DisableDefaultCmd: true},
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			return initStore(cmd, args)
+		},
 	}
 
 	var addCmd = &cobra.Command{
 		Use:   "add",
 		Short: "Add a new project",
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			// Check if -w flag is present
 			useWorkingDir, _ := cmd.Flags().GetBool("working-dir")
 
@@ -37,8 +50,7 @@ by storing their locations on the filesystem and providing quick navigation.`,
 				// Get current working directory
 				currentDir, err := os.Getwd()
 				if err != nil {
-					fmt.Println("Error getting current directory:", err)
-					return
+					return fmt.Errorf("getting current directory: %w", err)
 				}
 
 				// Extract folder name from path
@@ -55,113 +67,342 @@ by storing their locations on the filesystem and providing quick navigation.`,
 
 				// Validate input
 				if name == "" || path == "" {
-					fmt.Println("Error: Both name and path are required")
-					return
+					return fmt.Errorf("both name and path are required")
 				}
 			}
 
-			config.AddProject(name, path, category)
+			tags, _ := cmd.Flags().GetStringSlice("tag")
+			aliases, _ := cmd.Flags().GetStringSlice("alias")
+			description, _ := cmd.Flags().GetString("description")
+
+			return AddProject(config.Project{
+				Name:        name,
+				Path:        path,
+				Category:    category,
+				Tags:        config.NormalizeList(tags),
+				Aliases:     config.NormalizeList(aliases),
+				Description: description,
+			})
 		},
 	}
 
 	var removeCmd = &cobra.Command{
-		Use:   "remove",
+		Use:   "remove <project>",
 		Short: "Remove a project",
-		Args:  cobra.ExactArgs(1),
-		Run: func(cmd *cobra.Command, args []string) {
-			config.RemoveProject(args[0])
+		Long: `Remove a project by its name. Removing it by an alias asks for
+confirmation first, and its worktrees stay registered as projects of their own.`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeProjects,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			yes, _ := cmd.Flags().GetBool("yes")
+			return RemoveProject(args[0], yes)
 		},
 	}
 
-	var listCmd = &cobra.Command{
-		Use:   "list",
-		Short: "List all projects",
-		Run: func(cmd *cobra.Command, args []string) {
-			ListProjects()
-		},
-	}
+	removeCmd.Flags().BoolP("yes", "y", false, "Remove a project given by alias without asking")
 
 	var goCmd = &cobra.Command{
-		Use:   "go",
+		Use:   "go <query>",
 		Short: "Navigate to a project",
-		Args:  cobra.ExactArgs(1),
-		Run: func(cmd *cobra.Command, args []string) {
-			GoToProject(args[0])
+		Long: `Navigate to a project by name or alias. When nothing matches exactly, the
+query is matched against names, aliases and paths (fuzzily if no plain
+match exists) and the most frequently and recently used match wins. If
+several matches are equally likely, a small picker asks which one you meant.`,
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: completeProjects,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			listOnly, _ := cmd.Flags().GetBool("list")
+			exact, _ := cmd.Flags().GetBool("exact")
+			printPath, _ := cmd.Flags().GetBool("print-path")
+
+			query := ""
+			if len(args) > 0 {
+				query = args[0]
+			}
+
+			if listOnly {
+				return ListRankedProjects(query)
+			}
+			if query == "" {
+				return fmt.Errorf("a project name is required")
+			}
+			return GoToProject(query, exact, printPath)
 		},
 	}
 
 	var interactiveCmd = &cobra.Command{
 		Use:   "i",
 		Short: "Start interactive mode",
-		Run: func(cmd *cobra.Command, args []string) {
-			result := ui.RunInteractive()
+		RunE: func(cmd *cobra.Command, args []string) error {
+			result, err := ui.RunInteractive(store)
+			if err != nil {
+				return err
+			}
 			if result != "" {
-				fmt.Println(result)
+				return shell.Handoff(result)
 			}
+			return nil
 		},
 	}
 
+	rootCmd.PersistentFlags().String("config", "", "Config file to use (default $MPM_CONFIG or $XDG_CONFIG_HOME/mpm/config.json)")
+
 	addCmd.Flags().StringP("name", "n", "", "Project name")
 	addCmd.Flags().StringP("path", "p", "", "Project path")
 	addCmd.Flags().StringP("category", "c", "", "Project category (optional)")
 	addCmd.Flags().BoolP("working-dir", "w", false, "Use current directory as project path and folder name as project name")
+	addCmd.Flags().StringSliceP("tag", "t", nil, "Project tag (repeatable or comma-separated)")
+	addCmd.Flags().StringSliceP("alias", "a", nil, "Short alias for the project (repeatable or comma-separated)")
+	addCmd.Flags().StringP("description", "d", "", "Free-text project description")
+
+	goCmd.Flags().BoolP("list", "l", false, "List ranked candidates with their scores instead of navigating")
+	goCmd.Flags().BoolP("exact", "e", false, "Only accept an exact project name or alias (for scripts)")
+	goCmd.Flags().Bool("print-path", false, "Print the project path instead of a cd command (for scripts)")
+
+	addCmd.RegisterFlagCompletionFunc("category", completeCategories)
+	addCmd.RegisterFlagCompletionFunc("tag", completeTags)
 
 	rootCmd.AddCommand(addCmd)
 	rootCmd.AddCommand(removeCmd)
-	rootCmd.AddCommand(listCmd)
+	rootCmd.AddCommand(newListCmd())
 	rootCmd.AddCommand(goCmd)
+	rootCmd.AddCommand(newShowCmd())
+	rootCmd.AddCommand(newStatusCmd())
+	rootCmd.AddCommand(newOpenCmd())
+	rootCmd.AddCommand(newTmuxCmd())
+	rootCmd.AddCommand(newRunCmd())
+	rootCmd.AddCommand(newExecCmd())
+	rootCmd.AddCommand(newWorktreeCmd())
+	rootCmd.AddCommand(newEditCmd())
+	rootCmd.AddCommand(newRenameCmd())
+	rootCmd.AddCommand(newMoveCmd())
+	rootCmd.AddCommand(newScanCmd())
+	rootCmd.AddCommand(newDoctorCmd())
+	rootCmd.AddCommand(newRelocateCmd())
 	rootCmd.AddCommand(interactiveCmd)
+	rootCmd.AddCommand(newConfigCmd())
+	rootCmd.AddCommand(newInitCmd())
+	rootCmd.AddCommand(newCompletionCmd())
 
 	if err := rootCmd.Execute(); err != nil {
-		fmt.Println(err)
+		// A program run in the foreground, like a task, has reported its own
+		// error, only pass on its exit code
+		if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() > 0 {
+			os.Exit(exitErr.ExitCode())
+		}
+		fmt.Fprintln(os.Stderr, "Error:", err)
 		os.Exit(1)
 	}
 }
 
-// ListProjects displays all projects by category
-func ListProjects() {
-	cfg := config.LoadConfig()
+// initStore opens the config store and upgrades an older config file,
+// unless the command manages migrations itself
+func initStore(cmd *cobra.Command, args []string) error {
+	configPath, _ := cmd.Flags().GetString("config")
+
+	// Completion requests run on every <TAB> and only read the config, so
+	// skip the legacy move, file creation and migration
+	if cmd.Name() == cobra.ShellCompRequestCmd || cmd.Name() == cobra.ShellCompNoDescRequestCmd {
+		path, err := config.ResolveConfigPath(completionConfigFlag(args))
+		if err != nil {
+			return err
+		}
+		store = config.NewStore(path)
+		return nil
+	}
+
+	// Move a pre-XDG ~/.mpm config over unless another file was requested
+	if configPath == "" && os.Getenv("MPM_CONFIG") == "" {
+		moved, err := config.MigrateLegacyConfig()
+		if err != nil {
+			return err
+		}
+		if moved != "" {
+			fmt.Fprintf(os.Stderr, "Moved config from ~/.mpm to %s\n", moved)
+		}
+	}
+
+	var err error
+	store, err = config.InitConfig(configPath)
+	if err != nil {
+		return fmt.Errorf("initializing config: %w", err)
+	}
+
+	if cmd.Annotations[skipMigrateAnnotation] != "" {
+		return nil
+	}
+
+	report, err := store.Migrate(false)
+	if err != nil {
+		return err
+	}
+	if report.Changed() {
+		fmt.Fprintf(os.Stderr, "Migrated config from version %d to %d (backup: %s)\n",
+			report.FromVersion, report.ToVersion, report.BackupPath)
+	}
+
+	return nil
+}
+
+// completionConfigFlag finds --config in the command line being completed.
+// Cobra does not parse the flags of a completion request, so they arrive
+// as plain arguments. The last argument is the word being completed.
+func completionConfigFlag(args []string) string {
+	configPath := ""
+	for i := 0; i < len(args)-1; i++ {
+		arg := args[i]
+		if arg == "--" {
+			break
+		}
+		if value, ok := strings.CutPrefix(arg, "--config="); ok {
+			configPath = value
+		} else if arg == "--config" && i+1 < len(args)-1 {
+			configPath = args[i+1]
+			i++
+		}
+	}
+	return configPath
+}
+
+// AddProject adds or updates a project and reports what happened
+func AddProject(project config.Project) error {
+	updated, err := store.AddProject(project)
+	if err != nil {
+		return err
+	}
 
-	if len(cfg.Projects) == 0 {
-		fmt.Println("No projects found")
-		return
+	saved, err := store.GetProject(project.Name)
+	if err != nil {
+		return err
 	}
 
-	// Group projects by category
-	categories := make(map[string][]config.Project)
-	for _, p := range cfg.Projects {
-		if p.Category == "" {
-			p.Category = "Uncategorized"
+	if updated {
+		fmt.Printf("Updated project '%s' with path '%s' and category '%s'\n", saved.Name, saved.Path, saved.Category)
+	} else {
+		fmt.Printf("Added project '%s' with path '%s' and category '%s'\n", saved.Name, saved.Path, saved.Category)
+	}
+	return nil
+}
+
+// RemoveProject removes a project and reports the result. A project given
+// by one of its aliases is only removed once confirmed.
+func RemoveProject(name string, yes bool) error {
+	cfg, err := store.Load()
+	if err != nil {
+		return err
+	}
+	if project, ok := cfg.FindProject(name); ok && project.Name != name {
+		if !yes {
+			question := fmt.Sprintf("'%s' is an alias of '%s' (%s). Remove '%s'? [y/N] ", name, project.Name, project.Path, project.Name)
+			answer := prompt(bufio.NewReader(os.Stdin), question)
+			if !strings.EqualFold(answer, "y") && !strings.EqualFold(answer, "yes") {
+				fmt.Println("Nothing changed")
+				return nil
+			}
 		}
-		categories[p.Category] = append(categories[p.Category], p)
+		name = project.Name
 	}
 
-	// Sort categories
-	var sortedCategories []string
-	for c := range categories {
-		sortedCategories = append(sortedCategories, c)
+	if err := store.RemoveProject(name); err != nil {
+		if errors.Is(err, config.ErrProjectNotFound) {
+			fmt.Printf("Project '%s' not found\n", name)
+			return nil
+		}
+		return err
 	}
-	sort.Strings(sortedCategories)
 
-	// Display projects by category
-	for _, c := range sortedCategories {
-		fmt.Printf("\n[%s]\n", c)
-		projects := categories[c]
-		for _, p := range projects {
-			fmt.Printf("  - %s: %s\n", p.Name, p.Path)
+	fmt.Printf("Removed project '%s'\n", name)
+	return nil
+}
+
+// GoToProject hands the cd command for a project to the shell wrapper, or
+// prints its raw path for scripts
+func GoToProject(query string, exact, printPath bool) error {
+	project, err := resolveProject(query, exact)
+	if err != nil {
+		// Scripts reading the path need a failing exit status
+		if errors.Is(err, config.ErrProjectNotFound) && !printPath {
+			fmt.Printf("Project '%s' not found\n", query)
+			return nil
 		}
+		return err
+	}
+
+	if err := store.RecordOpen(project.Name); err != nil {
+		return err
+	}
+
+	if printPath {
+		fmt.Println(project.Path)
+		return nil
+	}
+
+	// The shell wrapper runs this in the calling shell
+	return shell.Handoff(shell.CdCommand(project.Path, ""))
+}
+
+// ListRankedProjects prints the projects matching query ordered by frecency
+func ListRankedProjects(query string) error {
+	cfg, err := store.Load()
+	if err != nil {
+		return err
+	}
+
+	ranked := config.MatchProjects(cfg.Projects, query, time.Now())
+	if len(ranked) == 0 {
+		fmt.Println("No matching projects found")
+		return nil
+	}
+
+	for _, r := range ranked {
+		fmt.Printf("%8.2f  %-24s %s\n", r.Score, r.Project.Name, r.Project.Path)
+	}
+	return nil
+}
+
+// resolveProject finds the project for a query: an exact name or alias
+// first, otherwise the best match, asking the user when it is ambiguous
+func resolveProject(query string, exact bool) (config.Project, error) {
+	cfg, err := store.Load()
+	if err != nil {
+		return config.Project{}, err
+	}
+
+	if project, ok := cfg.FindProject(query); ok {
+		return project, nil
+	}
+	if exact {
+		return config.Project{}, fmt.Errorf("%w: %s", config.ErrProjectNotFound, query)
+	}
+
+	candidates := config.MatchProjects(cfg.Projects, query, time.Now())
+	if len(candidates) == 0 {
+		return config.Project{}, fmt.Errorf("%w: %s", config.ErrProjectNotFound, query)
+	}
+	if config.UniqueBest(candidates) {
+		return candidates[0].Project, nil
 	}
+
+	return pickProject(query, candidates)
 }
 
-// GoToProject prints the path for a project to be used by a shell wrapper
-func GoToProject(name string) {
-	path := config.GoToProject(name)
-	if path != "" {
-		// We need to print the command to change directory
-		// The shell wrapper will execute it
-		fmt.Printf("cd %s\n", path)
-		return
+// pickProject lets the user choose between equally likely candidates
+func pickProject(query string, candidates []config.RankedProject) (config.Project, error) {
+	items := make([]ui.PickerItem, len(candidates))
+	names := make([]string, len(candidates))
+	for i, c := range candidates {
+		items[i] = ui.PickerItem{Title: c.Project.Name, Detail: c.Project.Path}
+		names[i] = c.Project.Name
+	}
+
+	choice, err := ui.RunPicker(fmt.Sprintf("Projects matching '%s'", query), items)
+	if err != nil {
+		// Typically there is no terminal to ask on, e.g. inside a script
+		return config.Project{}, fmt.Errorf("'%s' matches several projects (%s): %w", query, strings.Join(names, ", "), err)
+	}
+	if choice < 0 {
+		return config.Project{}, fmt.Errorf("no project selected")
 	}
 
-	fmt.Printf("Project '%s' not found\n", name)
+	return candidates[choice].Project, nil
 }
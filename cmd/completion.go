@@ -0,0 +1,187 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"mpm/pkg/config"
+	"mpm/pkg/launch"
+	"mpm/pkg/task"
+)
+
+// newCompletionCmd creates the `mpm completion` command
+func newCompletionCmd() *cobra.Command {
+	var completionCmd = &cobra.Command{
+		Use:   "completion <bash|zsh|fish|powershell>",
+		Short: "Print the shell completion script",
+		Long: `Print a completion script that completes commands, flags, project names,
+categories and tags. Load it from your shell startup file:
+
+  bash:  source <(mpm completion bash)       # ~/.bashrc
+  zsh:   source <(mpm completion zsh)        # ~/.zshrc
+  fish:  mpm completion fish | source        # ~/.config/fish/config.fish`,
+		Args:      cobra.ExactArgs(1),
+		ValidArgs: []string{"bash", "zsh", "fish", "powershell"},
+		// Runs on every shell startup, so skip loading the config
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			root := cmd.Root()
+			switch args[0] {
+			case "bash":
+				return root.GenBashCompletionV2(os.Stdout, true)
+			case "zsh":
+				return root.GenZshCompletion(os.Stdout)
+			case "fish":
+				return root.GenFishCompletion(os.Stdout, true)
+			case "powershell":
+				return root.GenPowerShellCompletionWithDesc(os.Stdout)
+			}
+			return fmt.Errorf("unsupported shell '%s' (supported: bash, zsh, fish, powershell)", args[0])
+		},
+	}
+
+	return completionCmd
+}
+
+// completeProjects completes the first argument with registered project
+// names and aliases, described by their path
+func completeProjects(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	cfg, ok := completionConfig()
+	if !ok {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	var completions []string
+	for _, p := range cfg.Projects {
+		if strings.HasPrefix(p.Name, toComplete) {
+			completions = append(completions, p.Name+"\t"+p.Path)
+		}
+		for _, alias := range p.Aliases {
+			if strings.HasPrefix(alias, toComplete) {
+				completions = append(completions, alias+"\t"+p.Name+": "+p.Path)
+			}
+		}
+	}
+
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeLaunchers completes a flag value with the configured launchers
+func completeLaunchers(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	cfg, ok := completionConfig()
+	if !ok {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	launchers, err := launch.Registry(cfg)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	var completions []string
+	for _, l := range launchers {
+		if strings.HasPrefix(l.Name, toComplete) {
+			completions = append(completions, l.Name+"\t"+l.Label)
+		}
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeTasks completes the project of `mpm run`, then its tasks,
+// described by their command
+func completeTasks(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) != 1 {
+		return completeProjects(cmd, args, toComplete)
+	}
+
+	cfg, ok := completionConfig()
+	if !ok {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	project, ok := cfg.FindProject(args[0])
+	if !ok {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	tasks, err := task.Discover(project)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	var completions []string
+	for _, t := range tasks {
+		if strings.HasPrefix(t.Name, toComplete) {
+			completions = append(completions, t.Name+"\t"+t.Command)
+		}
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeCategories completes a flag value with the categories in use
+func completeCategories(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return completeValues(toComplete, func(p config.Project) []string {
+		if p.Category == "" {
+			return nil
+		}
+		return []string{p.Category}
+	})
+}
+
+// completeTags completes a flag value with the tags in use
+func completeTags(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return completeValues(toComplete, func(p config.Project) []string {
+		return p.Tags
+	})
+}
+
+// completeValues completes toComplete with the distinct values extracted
+// from every project, each described by how many projects use it
+func completeValues(toComplete string, values func(config.Project) []string) ([]string, cobra.ShellCompDirective) {
+	cfg, ok := completionConfig()
+	if !ok {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	counts := make(map[string]int)
+	for _, p := range cfg.Projects {
+		for _, v := range values(p) {
+			if strings.HasPrefix(v, toComplete) {
+				counts[v]++
+			}
+		}
+	}
+
+	completions := make([]string, 0, len(counts))
+	for v, n := range counts {
+		label := "projects"
+		if n == 1 {
+			label = "project"
+		}
+		completions = append(completions, fmt.Sprintf("%s\t%d %s", v, n, label))
+	}
+	sort.Strings(completions)
+
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completionConfig loads the config for a completion request. Failures
+// yield no completions rather than errors in the middle of the command line.
+func completionConfig() (config.Config, bool) {
+	if store == nil {
+		return config.Config{}, false
+	}
+
+	cfg, err := store.Load()
+	if err != nil {
+		return config.Config{}, false
+	}
+	return cfg, true
+}
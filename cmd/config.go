@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// skipMigrateAnnotation marks commands that must see the config file
+// before it is automatically migrated
+const skipMigrateAnnotation = "mpm/skip-migrate"
+
+// newConfigCmd creates the `mpm config` command group
+func newConfigCmd() *cobra.Command {
+	var configCmd = &cobra.Command{
+		Use:   "config",
+		Short: "Inspect and maintain the configuration file",
+	}
+
+	var pathCmd = &cobra.Command{
+		Use:   "path",
+		Short: "Print the location of the configuration file",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			fmt.Println(store.Path())
+		},
+	}
+
+	var migrateCmd = &cobra.Command{
+		Use:         "migrate",
+		Short:       "Upgrade the configuration file to the current schema version",
+		Args:        cobra.NoArgs,
+		Annotations: map[string]string{skipMigrateAnnotation: "true"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dryRun, _ := cmd.Flags().GetBool("dry-run")
+			return MigrateConfig(dryRun)
+		},
+	}
+
+	migrateCmd.Flags().Bool("dry-run", false, "Show what would change without writing anything")
+
+	var categoryLauncherCmd = &cobra.Command{
+		Use:   "category-launcher <category> [<launcher>]",
+		Short: "Show or set the preferred launcher for a category",
+		Long: `Show or set the launcher 'mpm open' and Enter in the action view use for
+projects in a category that do not choose their own.`,
+		Args: cobra.RangeArgs(1, 2),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) == 0 {
+				return completeCategories(cmd, args, toComplete)
+			}
+			return completeLaunchers(cmd, args[1:], toComplete)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			unset, _ := cmd.Flags().GetBool("unset")
+			launcher := ""
+			if len(args) == 2 {
+				launcher = args[1]
+			}
+			return SetCategoryLauncher(args[0], launcher, unset)
+		},
+	}
+
+	categoryLauncherCmd.Flags().Bool("unset", false, "Remove the category's preferred launcher")
+
+	configCmd.AddCommand(pathCmd)
+	configCmd.AddCommand(migrateCmd)
+	configCmd.AddCommand(categoryLauncherCmd)
+
+	return configCmd
+}
+
+// MigrateConfig upgrades the config file and describes the applied steps
+func MigrateConfig(dryRun bool) error {
+	report, err := store.Migrate(dryRun)
+	if err != nil {
+		return err
+	}
+
+	if !report.Changed() {
+		fmt.Printf("Config is already at version %d, nothing to migrate\n", report.ToVersion)
+		return nil
+	}
+
+	if dryRun {
+		fmt.Printf("Would migrate %s from version %d to %d:\n", store.Path(), report.FromVersion, report.ToVersion)
+	} else {
+		fmt.Printf("Migrated %s from version %d to %d:\n", store.Path(), report.FromVersion, report.ToVersion)
+	}
+	for _, step := range report.Steps {
+		fmt.Printf("  - %s\n", step)
+	}
+
+	if dryRun {
+		fmt.Printf("\nResulting config:\n%s\n", report.After)
+	} else {
+		fmt.Printf("\nOriginal saved to %s\n", report.BackupPath)
+	}
+
+	return nil
+}
+
+// SetCategoryLauncher shows, sets or removes the preferred launcher of a
+// category
+func SetCategoryLauncher(category, launcher string, unset bool) error {
+	if unset {
+		if err := store.SetCategoryLauncher(category, ""); err != nil {
+			return err
+		}
+		fmt.Printf("Removed the preferred launcher of category '%s'\n", category)
+		return nil
+	}
+
+	if launcher == "" {
+		cfg, err := store.Load()
+		if err != nil {
+			return err
+		}
+		if current := cfg.CategoryLaunchers[category]; current != "" {
+			fmt.Println(current)
+		} else {
+			fmt.Printf("Category '%s' has no preferred launcher\n", category)
+		}
+		return nil
+	}
+
+	if err := checkLauncher(launcher); err != nil {
+		return err
+	}
+	if err := store.SetCategoryLauncher(category, launcher); err != nil {
+		return err
+	}
+	fmt.Printf("Projects in category '%s' now open with '%s'\n", category, launcher)
+	return nil
+}
@@ -0,0 +1,151 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"mpm/pkg/doctor"
+)
+
+// newDoctorCmd creates the `mpm doctor` command
+func newDoctorCmd() *cobra.Command {
+	var doctorCmd = &cobra.Command{
+		Use:   "doctor",
+		Short: "Find missing, duplicate and conflicting projects",
+		Long: `Check every registered project and report:
+  - projects whose path no longer exists
+  - projects that resolve to the same directory (e.g. through symlinks)
+  - separate registrations of the same git remote
+  - names that only differ in letter case
+
+With --fix, mpm offers to prune, relocate or merge the affected projects.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			fix, _ := cmd.Flags().GetBool("fix")
+			asJSON, _ := cmd.Flags().GetBool("json")
+			return RunDoctor(fix, asJSON)
+		},
+	}
+
+	doctorCmd.Flags().Bool("fix", false, "Interactively prune, relocate or merge affected projects")
+	doctorCmd.Flags().Bool("json", false, "Print the issues as JSON")
+
+	return doctorCmd
+}
+
+// RunDoctor diagnoses the registry and optionally fixes the issues found
+func RunDoctor(fix, asJSON bool) error {
+	cfg, err := store.Load()
+	if err != nil {
+		return err
+	}
+
+	issues := doctor.Diagnose(cfg)
+	if asJSON {
+		if issues == nil {
+			issues = []doctor.Issue{}
+		}
+		return printJSON(issues)
+	}
+
+	if len(issues) == 0 {
+		fmt.Printf("All %d projects look healthy\n", len(cfg.Projects))
+		return nil
+	}
+
+	fmt.Printf("Found %d issues:\n", len(issues))
+	for _, issue := range issues {
+		fmt.Printf("  - %s\n", issue)
+	}
+
+	if !fix {
+		fmt.Println("\nRun 'mpm doctor --fix' to resolve them")
+		return fmt.Errorf("found %d issues", len(issues))
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	for _, issue := range issues {
+		fmt.Println()
+		if err := fixIssue(reader, issue); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// fixIssue asks how to resolve a single issue and applies the answer
+func fixIssue(reader *bufio.Reader, issue doctor.Issue) error {
+	// An earlier fix may already have removed some of the projects
+	cfg, err := store.Load()
+	if err != nil {
+		return err
+	}
+	registered := make(map[string]bool)
+	for _, p := range cfg.Projects {
+		registered[p.Name] = true
+	}
+	var names []string
+	for _, name := range issue.Projects {
+		if registered[name] {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 || (issue.Kind != doctor.MissingPath && len(names) < 2) {
+		return nil
+	}
+
+	fmt.Println(issue)
+
+	if issue.Kind == doctor.MissingPath {
+		name := names[0]
+		switch strings.ToLower(prompt(reader, "[p]rune, [r]elocate or [s]kip? ")) {
+		case "p":
+			if err := store.RemoveProject(name); err != nil {
+				return err
+			}
+			fmt.Printf("Removed project '%s'\n", name)
+		case "r":
+			path := prompt(reader, "New path: ")
+			if path == "" {
+				return nil
+			}
+			return MoveProject(name, path, false)
+		}
+		return nil
+	}
+
+	for i, name := range names {
+		fmt.Printf("  [%d] keep '%s'\n", i+1, name)
+	}
+	answer := prompt(reader, "Merge the others into which project (number) or [s]kip? ")
+	choice, err := strconv.Atoi(answer)
+	if err != nil || choice < 1 || choice > len(names) {
+		return nil
+	}
+
+	keep := names[choice-1]
+	var others []string
+	for _, name := range names {
+		if name != keep {
+			others = append(others, name)
+		}
+	}
+	if err := store.MergeProjects(keep, others); err != nil {
+		return err
+	}
+	fmt.Printf("Merged %s into '%s'\n", strings.Join(others, ", "), keep)
+	return nil
+}
+
+// prompt prints a question and returns the trimmed answer
+func prompt(reader *bufio.Reader, question string) string {
+	fmt.Print(question)
+	answer, _ := reader.ReadString('\n')
+	return strings.TrimSpace(answer)
+}
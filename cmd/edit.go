@@ -0,0 +1,193 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"mpm/pkg/config"
+)
+
+// newEditCmd creates the `mpm edit` command
+func newEditCmd() *cobra.Command {
+	var editCmd = &cobra.Command{
+		Use:   "edit <project>",
+		Short: "Change a project's category, tags, description or aliases",
+		Long: `Change the metadata of a registered project. Only the flags you pass are
+applied; everything else is left untouched.`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeProjects,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			flags := cmd.Flags()
+
+			// Global flags like --config change nothing about the project
+			changed := false
+			cmd.LocalNonPersistentFlags().VisitAll(func(f *pflag.Flag) {
+				changed = changed || f.Changed
+			})
+			if !changed {
+				return fmt.Errorf("nothing to change, see 'mpm edit --help'")
+			}
+
+			if launcher, _ := flags.GetString("launcher"); launcher != "" {
+				if err := checkLauncher(launcher); err != nil {
+					return err
+				}
+			}
+
+			return EditProject(args[0], func(p *config.Project) error {
+				if flags.Changed("category") {
+					p.Category, _ = flags.GetString("category")
+				}
+				if flags.Changed("description") {
+					p.Description, _ = flags.GetString("description")
+				}
+				if flags.Changed("tag") {
+					tags, _ := flags.GetStringSlice("tag")
+					p.Tags = config.NormalizeList(tags)
+				}
+				if flags.Changed("add-tag") {
+					tags, _ := flags.GetStringSlice("add-tag")
+					p.Tags = config.NormalizeList(append(p.Tags, tags...))
+				}
+				if flags.Changed("remove-tag") {
+					tags, _ := flags.GetStringSlice("remove-tag")
+					p.Tags = removeValues(p.Tags, tags)
+				}
+				if flags.Changed("alias") {
+					aliases, _ := flags.GetStringSlice("alias")
+					p.Aliases = config.NormalizeList(aliases)
+				}
+				if flags.Changed("add-alias") {
+					aliases, _ := flags.GetStringSlice("add-alias")
+					p.Aliases = config.NormalizeList(append(p.Aliases, aliases...))
+				}
+				if flags.Changed("remove-alias") {
+					aliases, _ := flags.GetStringSlice("remove-alias")
+					p.Aliases = removeValues(p.Aliases, aliases)
+				}
+				if flags.Changed("launcher") {
+					p.Launcher, _ = flags.GetString("launcher")
+				}
+				return nil
+			})
+		},
+	}
+
+	editCmd.Flags().StringP("category", "c", "", "Set the category")
+	editCmd.Flags().StringP("description", "d", "", "Set the description")
+	editCmd.Flags().StringSliceP("tag", "t", nil, "Replace all tags")
+	editCmd.Flags().StringSlice("add-tag", nil, "Add tags")
+	editCmd.Flags().StringSlice("remove-tag", nil, "Remove tags")
+	editCmd.Flags().StringSliceP("alias", "a", nil, "Replace all aliases")
+	editCmd.Flags().StringSlice("add-alias", nil, "Add aliases")
+	editCmd.Flags().StringSlice("remove-alias", nil, "Remove aliases")
+	editCmd.Flags().String("launcher", "", "Set the preferred launcher for 'mpm open' (empty to use the default)")
+
+	editCmd.RegisterFlagCompletionFunc("category", completeCategories)
+	editCmd.RegisterFlagCompletionFunc("launcher", completeLaunchers)
+	for _, flag := range []string{"tag", "add-tag", "remove-tag"} {
+		editCmd.RegisterFlagCompletionFunc(flag, completeTags)
+	}
+
+	return editCmd
+}
+
+// newRenameCmd creates the `mpm rename` command
+func newRenameCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:               "rename <project> <new-name>",
+		Short:             "Rename a project",
+		Args:              cobra.ExactArgs(2),
+		ValidArgsFunction: completeProjects,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return RenameProject(args[0], args[1])
+		},
+	}
+}
+
+// newMoveCmd creates the `mpm mv` command
+func newMoveCmd() *cobra.Command {
+	var moveCmd = &cobra.Command{
+		Use:   "mv <project> <new-path>",
+		Short: "Point a project at a different directory",
+		Long: `Point a registered project at a different directory. Only the stored path
+changes; nothing is moved on disk.`,
+		Args: cobra.ExactArgs(2),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) == 1 {
+				return nil, cobra.ShellCompDirectiveFilterDirs
+			}
+			return completeProjects(cmd, args, toComplete)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			force, _ := cmd.Flags().GetBool("force")
+			return MoveProject(args[0], args[1], force)
+		},
+	}
+
+	moveCmd.Flags().BoolP("force", "f", false, "Accept a path that does not exist (yet)")
+
+	return moveCmd
+}
+
+// RenameProject renames a project and reports the result
+func RenameProject(name, newName string) error {
+	if err := store.RenameProject(name, newName); err != nil {
+		return err
+	}
+
+	fmt.Printf("Renamed project '%s' to '%s'\n", name, newName)
+	return nil
+}
+
+// MoveProject changes a project's path and reports the result
+func MoveProject(name, newPath string, force bool) error {
+	absPath, err := config.ExpandPath(newPath)
+	if err != nil {
+		return err
+	}
+
+	if info, err := os.Stat(absPath); err != nil || !info.IsDir() {
+		if !force {
+			return fmt.Errorf("'%s' is not an existing directory (use --force to set it anyway)", absPath)
+		}
+	}
+
+	if err := store.MoveProject(name, absPath); err != nil {
+		return err
+	}
+
+	fmt.Printf("Moved project '%s' to '%s'\n", name, absPath)
+	return nil
+}
+
+// EditProject applies fn to a project and reports the result
+func EditProject(name string, fn func(*config.Project) error) error {
+	if err := store.UpdateProject(name, fn); err != nil {
+		return err
+	}
+
+	fmt.Printf("Updated project '%s'\n", name)
+	return nil
+}
+
+// removeValues returns values without any of the entries in remove
+func removeValues(values, remove []string) []string {
+	var result []string
+	for _, v := range values {
+		keep := true
+		for _, r := range remove {
+			if v == r {
+				keep = false
+				break
+			}
+		}
+		if keep {
+			result = append(result, v)
+		}
+	}
+	return result
+}
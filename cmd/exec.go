@@ -0,0 +1,237 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"mpm/pkg/config"
+	"mpm/pkg/fs"
+)
+
+// execOptions selects the projects `mpm exec` runs a command in
+type execOptions struct {
+	All      bool
+	Category string
+	Tags     []string
+	Filter   string
+	Jobs     int
+}
+
+// execResult is the outcome of the command in one project
+type execResult struct {
+	Name     string
+	Err      error
+	Duration time.Duration
+}
+
+// newExecCmd creates the `mpm exec` command
+func newExecCmd() *cobra.Command {
+	var execCmd = &cobra.Command{
+		Use:   "exec (--all | --category <c> | --tag <t> | --filter <pattern>) -- <command> [<args>...]",
+		Short: "Run a command in several project directories in parallel",
+		Long: `Run a command in the directory of every selected project, several at a
+time, with each output line prefixed by the project name. A summary of the
+projects where the command failed is printed at the end, and mpm exits with
+an error if there were any.
+
+Selectors combine: --category backend --tag go runs in projects that match
+both. --filter matches project names and aliases, as a glob pattern when it
+contains *, ? or [, and as a substring otherwise. --all is required to run
+in every project.
+
+A single argument is run by the shell, so it can use pipes and &&:
+
+  mpm exec --category backend -- git pull --ff-only
+  mpm exec --all -j 4 -- 'git fetch && git status -sb'`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var opts execOptions
+			opts.All, _ = cmd.Flags().GetBool("all")
+			opts.Category, _ = cmd.Flags().GetString("category")
+			opts.Tags, _ = cmd.Flags().GetStringSlice("tag")
+			opts.Filter, _ = cmd.Flags().GetString("filter")
+			opts.Jobs, _ = cmd.Flags().GetInt("jobs")
+			return ExecProjects(opts, args)
+		},
+	}
+
+	// Flags after the command belong to it, even without --
+	execCmd.Flags().SetInterspersed(false)
+	execCmd.Flags().Bool("all", false, "Run in every project")
+	execCmd.Flags().StringP("category", "c", "", "Run in projects of this category")
+	execCmd.Flags().StringSliceP("tag", "t", nil, "Run in projects with this tag (repeatable, all must match)")
+	execCmd.Flags().StringP("filter", "f", "", "Run in projects whose name or alias matches this pattern")
+	execCmd.Flags().IntP("jobs", "j", 8, "Number of projects the command runs in at the same time")
+
+	execCmd.RegisterFlagCompletionFunc("category", completeCategories)
+	execCmd.RegisterFlagCompletionFunc("tag", completeTags)
+
+	return execCmd
+}
+
+// ExecProjects runs a command in every selected project and fails if it
+// failed in any of them
+func ExecProjects(opts execOptions, command []string) error {
+	if !opts.All && opts.Category == "" && len(opts.Tags) == 0 && opts.Filter == "" {
+		return fmt.Errorf("select projects with --category, --tag or --filter, or use --all")
+	}
+	if opts.Jobs < 1 {
+		return fmt.Errorf("--jobs must be at least 1")
+	}
+	if opts.Filter != "" {
+		if _, err := filepath.Match(opts.Filter, ""); err != nil {
+			return fmt.Errorf("invalid filter '%s': %w", opts.Filter, err)
+		}
+	}
+
+	cfg, err := store.Load()
+	if err != nil {
+		return err
+	}
+
+	var projects []config.Project
+	for _, p := range filterProjects(cfg.Projects, opts.Category, opts.Tags) {
+		if opts.Filter == "" || matchesFilter(p, opts.Filter) {
+			projects = append(projects, p)
+		}
+	}
+	if len(projects) == 0 {
+		return fmt.Errorf("no projects match the selection")
+	}
+
+	results := runInProjects(projects, command, opts.Jobs)
+
+	failed := 0
+	fmt.Println()
+	for _, r := range results {
+		if r.Err != nil {
+			failed++
+			fmt.Printf("✗ %s: %v (%s)\n", r.Name, r.Err, r.Duration.Round(10*time.Millisecond))
+		} else {
+			fmt.Printf("✓ %s (%s)\n", r.Name, r.Duration.Round(10*time.Millisecond))
+		}
+	}
+	fmt.Printf("\n%d succeeded, %d failed\n", len(results)-failed, failed)
+
+	if failed > 0 {
+		return fmt.Errorf("command failed in %d of %d projects", failed, len(results))
+	}
+	return nil
+}
+
+// matchesFilter reports whether a project's name or one of its aliases
+// matches pattern, as a glob when it has wildcards and a case-insensitive
+// substring otherwise
+func matchesFilter(p config.Project, pattern string) bool {
+	glob := strings.ContainsAny(pattern, "*?[")
+	for _, name := range append([]string{p.Name}, p.Aliases...) {
+		if glob {
+			if ok, _ := filepath.Match(pattern, name); ok {
+				return true
+			}
+		} else if strings.Contains(strings.ToLower(name), strings.ToLower(pattern)) {
+			return true
+		}
+	}
+	return false
+}
+
+// runInProjects runs the command in every project with a bounded number of
+// workers and returns the results in the order of projects
+func runInProjects(projects []config.Project, command []string, jobs int) []execResult {
+	width := 0
+	for _, p := range projects {
+		width = max(width, len(p.Name))
+	}
+
+	// Lines of different projects may interleave, but never break
+	var mu sync.Mutex
+	results := make([]execResult, len(projects))
+	forEachProject(projects, jobs, func(i int, p config.Project) {
+		prefix := fmt.Sprintf("%-*s | ", width, p.Name)
+		stdout := &prefixWriter{out: os.Stdout, prefix: prefix, mu: &mu}
+		stderr := &prefixWriter{out: os.Stderr, prefix: prefix, mu: &mu}
+
+		start := time.Now()
+		err := runInProject(p, command, stdout, stderr)
+		stdout.Flush()
+		stderr.Flush()
+		results[i] = execResult{Name: p.Name, Err: err, Duration: time.Since(start)}
+	})
+
+	return results
+}
+
+// runInProject runs the command in a project directory. A single argument
+// is handed to the shell.
+func runInProject(p config.Project, command []string, stdout, stderr io.Writer) error {
+	if !fs.PathExists(p.Path) {
+		return fmt.Errorf("directory %s does not exist", p.Path)
+	}
+
+	var cmd *exec.Cmd
+	switch {
+	case len(command) > 1:
+		cmd = exec.Command(command[0], command[1:]...)
+	case runtime.GOOS == "windows":
+		cmd = exec.Command("cmd", "/C", command[0])
+	default:
+		cmd = exec.Command("sh", "-c", command[0])
+	}
+	cmd.Dir = p.Path
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	return cmd.Run()
+}
+
+// prefixWriter writes whole lines to out, each starting with prefix.
+// Writers sharing mu never interleave within a line.
+type prefixWriter struct {
+	out    io.Writer
+	prefix string
+	mu     *sync.Mutex
+	buf    []byte
+}
+
+// Write implements io.Writer, holding back an unfinished last line
+func (w *prefixWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	i := bytes.LastIndexByte(w.buf, '\n')
+	if i < 0 {
+		return len(p), nil
+	}
+
+	lines := w.buf[:i+1]
+	var b bytes.Buffer
+	for _, line := range bytes.SplitAfter(lines, []byte("\n")) {
+		if len(line) > 0 {
+			b.WriteString(w.prefix)
+			b.Write(line)
+		}
+	}
+	w.buf = append([]byte(nil), w.buf[i+1:]...)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, err := w.out.Write(b.Bytes()); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Flush writes an unfinished last line
+func (w *prefixWriter) Flush() {
+	if len(w.buf) > 0 {
+		w.Write([]byte("\n"))
+	}
+}
@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"mpm/pkg/shell"
+)
+
+// newInitCmd creates the `mpm init` command
+func newInitCmd() *cobra.Command {
+	var initCmd = &cobra.Command{
+		Use:   "init <" + strings.Join(shell.Shells, "|") + ">",
+		Short: "Print the shell integration script",
+		Long: `Print a shell function that wraps mpm so 'mpm go' and 'mpm i' change the
+directory of the current shell. Add it to your shell startup file:
+
+  bash:  eval "$(mpm init bash)"     # ~/.bashrc
+  zsh:   eval "$(mpm init zsh)"      # ~/.zshrc
+  fish:  mpm init fish | source      # ~/.config/fish/config.fish`,
+		Args:      cobra.ExactArgs(1),
+		ValidArgs: shell.Shells,
+		// Runs on every shell startup, so skip loading the config
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			alias, _ := cmd.Flags().GetString("alias")
+			key, _ := cmd.Flags().GetString("key")
+
+			script, err := shell.Script(args[0], shell.Options{Alias: alias, Key: key})
+			if err != nil {
+				return err
+			}
+
+			fmt.Print(script)
+			return nil
+		},
+	}
+
+	initCmd.Flags().String("alias", "", "Also define a short command for 'mpm go', e.g. j")
+	initCmd.Flags().String("key", "", "Bind a key that opens interactive mode, e.g. ctrl-g")
+
+	return initCmd
+}
@@ -0,0 +1,270 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/spf13/cobra"
+
+	"mpm/pkg/config"
+	"mpm/pkg/format"
+	"mpm/pkg/tmux"
+)
+
+// listFormats are the accepted values of `mpm list --format`
+var listFormats = []string{"tree", "json", "yaml", "tsv"}
+
+// listSorts are the accepted values of `mpm list --sort`
+var listSorts = []string{"name", "category", "path", "opened", "used", "created"}
+
+// listOptions selects, orders and formats the projects printed by `mpm list`
+type listOptions struct {
+	GroupBy  string
+	Format   string
+	Template string
+	Category string
+	Tags     []string
+	Sort     string
+}
+
+// newListCmd creates the `mpm list` command
+func newListCmd() *cobra.Command {
+	var listCmd = &cobra.Command{
+		Use:   "list",
+		Short: "List all projects",
+		Long: `List registered projects, grouped by category or tag by default.
+
+For scripts, --format prints json, yaml or tsv (name, category, path, tags,
+aliases, description) and --template runs a Go template for every project:
+
+  mpm list --format json | jq -r '.[].path'
+  mpm list --template '{{.Name}}\t{{.Path}}' | fzf`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var opts listOptions
+			opts.GroupBy, _ = cmd.Flags().GetString("group-by")
+			opts.Format, _ = cmd.Flags().GetString("format")
+			opts.Template, _ = cmd.Flags().GetString("template")
+			opts.Category, _ = cmd.Flags().GetString("category")
+			opts.Tags, _ = cmd.Flags().GetStringSlice("tag")
+			opts.Sort, _ = cmd.Flags().GetString("sort")
+
+			if opts.Template != "" && cmd.Flags().Changed("format") {
+				return fmt.Errorf("--format and --template cannot be combined")
+			}
+			return ListProjects(opts)
+		},
+	}
+
+	listCmd.Flags().StringP("group-by", "g", "category", "Group projects by 'category' or 'tag'")
+	listCmd.Flags().StringP("format", "f", "tree", "Output format: "+strings.Join(listFormats, ", "))
+	listCmd.Flags().String("template", "", "Go template applied to every project, e.g. '{{.Name}}\\t{{.Path}}'")
+	listCmd.Flags().StringP("category", "c", "", "Only list projects in this category")
+	listCmd.Flags().StringSliceP("tag", "t", nil, "Only list projects with this tag (repeatable, all must match)")
+	listCmd.Flags().StringP("sort", "s", "", "Sort by "+strings.Join(listSorts, ", ")+" (default: registration order)")
+
+	listCmd.RegisterFlagCompletionFunc("group-by", cobra.FixedCompletions([]string{"category", "tag"}, cobra.ShellCompDirectiveNoFileComp))
+	listCmd.RegisterFlagCompletionFunc("format", cobra.FixedCompletions(listFormats, cobra.ShellCompDirectiveNoFileComp))
+	listCmd.RegisterFlagCompletionFunc("sort", cobra.FixedCompletions(listSorts, cobra.ShellCompDirectiveNoFileComp))
+	listCmd.RegisterFlagCompletionFunc("category", completeCategories)
+	listCmd.RegisterFlagCompletionFunc("tag", completeTags)
+
+	return listCmd
+}
+
+// ListProjects prints the selected projects in the requested format
+func ListProjects(opts listOptions) error {
+	if opts.GroupBy != "category" && opts.GroupBy != "tag" {
+		return fmt.Errorf("invalid group-by value '%s' (use 'category' or 'tag')", opts.GroupBy)
+	}
+
+	cfg, err := store.Load()
+	if err != nil {
+		return err
+	}
+
+	projects := filterProjects(cfg.Projects, opts.Category, opts.Tags)
+	if err := sortProjects(projects, opts.Sort); err != nil {
+		return err
+	}
+
+	if opts.Template != "" {
+		return printTemplate(projects, opts.Template)
+	}
+
+	switch opts.Format {
+	case "tree":
+		printTree(projects, opts.GroupBy)
+		return nil
+	case "json":
+		return printJSON(projects)
+	case "yaml":
+		data, err := format.YAML(projects)
+		if err != nil {
+			return err
+		}
+		_, err = os.Stdout.Write(data)
+		return err
+	case "tsv":
+		printTSV(projects)
+		return nil
+	}
+
+	return fmt.Errorf("invalid format '%s' (use %s)", opts.Format, strings.Join(listFormats, ", "))
+}
+
+// filterProjects keeps the projects in category (if set) that carry every
+// one of tags
+func filterProjects(projects []config.Project, category string, tags []string) []config.Project {
+	filtered := []config.Project{}
+	for _, p := range projects {
+		if category != "" && !strings.EqualFold(p.Category, category) {
+			continue
+		}
+
+		matches := true
+		for _, t := range tags {
+			matches = matches && p.HasTag(t)
+		}
+		if matches {
+			filtered = append(filtered, p)
+		}
+	}
+	return filtered
+}
+
+// sortProjects orders projects in place by the given key, keeping
+// registration order when the key is empty
+func sortProjects(projects []config.Project, by string) error {
+	var less func(a, b config.Project) bool
+	switch by {
+	case "":
+		return nil
+	case "name":
+		less = func(a, b config.Project) bool { return strings.ToLower(a.Name) < strings.ToLower(b.Name) }
+	case "category":
+		less = func(a, b config.Project) bool { return strings.ToLower(a.Category) < strings.ToLower(b.Category) }
+	case "path":
+		less = func(a, b config.Project) bool { return a.Path < b.Path }
+	case "opened":
+		less = func(a, b config.Project) bool { return a.LastOpened.After(b.LastOpened) }
+	case "used":
+		less = func(a, b config.Project) bool { return a.OpenCount > b.OpenCount }
+	case "created":
+		less = func(a, b config.Project) bool { return a.CreatedAt.Before(b.CreatedAt) }
+	default:
+		return fmt.Errorf("invalid sort key '%s' (use %s)", by, strings.Join(listSorts, ", "))
+	}
+
+	sort.SliceStable(projects, func(i, j int) bool {
+		return less(projects[i], projects[j])
+	})
+	return nil
+}
+
+// printTree prints projects grouped by category, or by every tag they carry
+func printTree(projects []config.Project, groupBy string) {
+	if len(projects) == 0 {
+		fmt.Println("No projects found")
+		return
+	}
+
+	groups := make(map[string][]config.Project)
+	for _, p := range projects {
+		if groupBy == "tag" {
+			if len(p.Tags) == 0 {
+				groups["Untagged"] = append(groups["Untagged"], p)
+			}
+			for _, t := range p.Tags {
+				groups[t] = append(groups[t], p)
+			}
+			continue
+		}
+
+		if p.Category == "" {
+			p.Category = "Uncategorized"
+		}
+		groups[p.Category] = append(groups[p.Category], p)
+	}
+
+	// Sort groups
+	var sortedGroups []string
+	for g := range groups {
+		sortedGroups = append(sortedGroups, g)
+	}
+	sort.Strings(sortedGroups)
+
+	// Mark projects with a running tmux session
+	sessions := tmux.Sessions()
+
+	// Display projects by group
+	for _, g := range sortedGroups {
+		if groupBy == "tag" {
+			fmt.Printf("\n#%s\n", g)
+		} else {
+			fmt.Printf("\n[%s]\n", g)
+		}
+		for _, p := range groups[g] {
+			name := p.Name
+			if len(p.Aliases) > 0 {
+				name = fmt.Sprintf("%s (%s)", p.Name, strings.Join(p.Aliases, ", "))
+			}
+			if sessions[tmux.SessionName(p.Name)] {
+				name += " [tmux]"
+			}
+			fmt.Printf("  - %s: %s\n", name, p.Path)
+			if p.Description != "" {
+				fmt.Printf("      %s\n", p.Description)
+			}
+		}
+	}
+}
+
+// tsvEscaper keeps every project on one line with a fixed number of columns
+var tsvEscaper = strings.NewReplacer("\\", "\\\\", "\t", "\\t", "\n", "\\n", "\r", "\\r")
+
+// printTSV prints one tab-separated line per project: name, category,
+// path, tags, aliases and description, lists joined by commas
+func printTSV(projects []config.Project) {
+	for _, p := range projects {
+		fields := []string{
+			p.Name,
+			p.Category,
+			p.Path,
+			strings.Join(p.Tags, ","),
+			strings.Join(p.Aliases, ","),
+			p.Description,
+		}
+		for i, f := range fields {
+			fields[i] = tsvEscaper.Replace(f)
+		}
+		fmt.Println(strings.Join(fields, "\t"))
+	}
+}
+
+// printTemplate executes a text/template over every project. Literal \t
+// and \n in the template are unescaped so it can be written in single
+// quotes on the command line, and each project ends with a newline.
+func printTemplate(projects []config.Project, text string) error {
+	text = strings.NewReplacer(`\t`, "\t", `\n`, "\n").Replace(text)
+	if !strings.HasSuffix(text, "\n") {
+		text += "\n"
+	}
+
+	tmpl, err := template.New("list").Funcs(template.FuncMap{
+		"join": strings.Join,
+	}).Parse(text)
+	if err != nil {
+		return fmt.Errorf("parsing template: %w", err)
+	}
+
+	for _, p := range projects {
+		if err := tmpl.Execute(os.Stdout, p); err != nil {
+			return fmt.Errorf("executing template: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"mpm/pkg/config"
+	"mpm/pkg/launch"
+	"mpm/pkg/shell"
+)
+
+// newOpenCmd creates the `mpm open` command
+func newOpenCmd() *cobra.Command {
+	var openCmd = &cobra.Command{
+		Use:   "open <project>",
+		Short: "Open a project in an editor or file manager",
+		Long: `Open a project with one of the launchers from interactive mode. The project
+is looked up like 'mpm go' does.
+
+Without --with the preferred launcher is used: the project's own (mpm edit
+--launcher), then its category's (mpm config category-launcher), then an
+installed IDE for the project type (GoLand for go.mod, PyCharm for
+pyproject.toml or requirements.txt, IntelliJ IDEA for pom.xml or
+build.gradle), then $VISUAL or $EDITOR, and finally VS Code.
+
+Built-in launchers: ` + strings.Join(launch.Names(launch.Builtin), ", ") + `
+More can be added in the "launchers" section of the config file.`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeProjects,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			with, _ := cmd.Flags().GetString("with")
+			return OpenProject(args[0], with)
+		},
+	}
+
+	openCmd.Flags().StringP("with", "w", "", "Launcher to use, e.g. "+strings.Join(launch.Names(launch.Builtin), ", "))
+
+	openCmd.RegisterFlagCompletionFunc("with", completeLaunchers)
+
+	return openCmd
+}
+
+// OpenProject opens a project with the named launcher, or the project's
+// preferred one when with is empty
+func OpenProject(query, with string) error {
+	cfg, err := store.Load()
+	if err != nil {
+		return err
+	}
+	launchers, err := launch.Registry(cfg)
+	if err != nil {
+		return err
+	}
+
+	project, err := resolveProject(query, false)
+	if err != nil {
+		return err
+	}
+
+	launcher, err := chooseLauncher(launchers, cfg, project, with)
+	if err != nil {
+		return err
+	}
+
+	if launcher.Mode == launch.Shell {
+		var command string
+		if command, err = launcher.ShellCommand(project); err == nil {
+			err = shell.Handoff(command)
+		}
+	} else {
+		err = launcher.Run(project)
+	}
+	if err != nil {
+		return err
+	}
+
+	return store.RecordOpen(project.Name)
+}
+
+// chooseLauncher returns the launcher named with, or the project's
+// preferred launcher
+func chooseLauncher(launchers []launch.Launcher, cfg config.Config, project config.Project, with string) (launch.Launcher, error) {
+	if with == "" {
+		return launch.Preferred(launchers, cfg.CategoryLaunchers, project)
+	}
+
+	launcher, ok := launch.Find(launchers, with)
+	if !ok {
+		return launch.Launcher{}, fmt.Errorf("unknown launcher '%s' (use %s)", with, strings.Join(launch.Names(launchers), ", "))
+	}
+	return launcher, nil
+}
+
+// checkLauncher fails unless name is a known launcher
+func checkLauncher(name string) error {
+	cfg, err := store.Load()
+	if err != nil {
+		return err
+	}
+	launchers, err := launch.Registry(cfg)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := launch.Find(launchers, name); !ok {
+		return fmt.Errorf("unknown launcher '%s' (use %s)", name, strings.Join(launch.Names(launchers), ", "))
+	}
+	return nil
+}
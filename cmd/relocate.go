@@ -0,0 +1,224 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"mpm/pkg/config"
+	"mpm/pkg/fs"
+)
+
+// newRelocateCmd creates the `mpm relocate` command
+func newRelocateCmd() *cobra.Command {
+	var relocateCmd = &cobra.Command{
+		Use:   "relocate [<old-prefix> <new-prefix>]",
+		Short: "Update project paths after moving directories",
+		Long: `Rewrite the paths of all projects under <old-prefix> to live under
+<new-prefix>, e.g. after moving ~/code to ~/src.
+
+With --search <dir>, projects whose directory no longer exists are looked
+for under <dir> instead, matching repositories by their git remote URL or
+first commit hash as remembered by mpm. Projects without a remembered
+identity are matched by their directory name.
+
+The changes are shown before anything is saved.`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if search, _ := cmd.Flags().GetString("search"); search != "" {
+				return cobra.NoArgs(cmd, args)
+			}
+			return cobra.ExactArgs(2)(cmd, args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			search, _ := cmd.Flags().GetString("search")
+			depth, _ := cmd.Flags().GetInt("depth")
+			dryRun, _ := cmd.Flags().GetBool("dry-run")
+			yes, _ := cmd.Flags().GetBool("yes")
+
+			var changes map[string]string
+			var err error
+			if search != "" {
+				changes, err = findMovedProjects(search, depth, dryRun)
+			} else {
+				changes, err = prefixChanges(args[0], args[1])
+			}
+			if err != nil {
+				return err
+			}
+
+			return applyRelocation(changes, dryRun, yes)
+		},
+	}
+
+	relocateCmd.Flags().StringP("search", "s", "", "Find moved repositories under this directory")
+	relocateCmd.Flags().IntP("depth", "d", 4, "Maximum directory depth for --search (0 for unlimited)")
+	relocateCmd.Flags().BoolP("dry-run", "n", false, "Only show what would change")
+	relocateCmd.Flags().BoolP("yes", "y", false, "Save without asking for confirmation")
+
+	return relocateCmd
+}
+
+// prefixChanges computes new paths for all projects under oldPrefix
+func prefixChanges(oldPrefix, newPrefix string) (map[string]string, error) {
+	oldPrefix, err := config.ExpandPath(oldPrefix)
+	if err != nil {
+		return nil, err
+	}
+	newPrefix, err = config.ExpandPath(newPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, err := store.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make(map[string]string)
+	for _, p := range cfg.Projects {
+		rel, err := filepath.Rel(oldPrefix, p.Path)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		changes[p.Name] = filepath.Join(newPrefix, rel)
+	}
+
+	return changes, nil
+}
+
+// findMovedProjects searches dir for the repositories of projects whose
+// path no longer exists. A dry run leaves the config file untouched.
+func findMovedProjects(dir string, depth int, dryRun bool) (map[string]string, error) {
+	dir, err := config.ExpandPath(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	// Remember the identity of repositories that are still in place, so
+	// they can be found the next time they move. This run only looks for
+	// missing ones, so a dry run can skip it.
+	if !dryRun {
+		if err := store.RememberIdentities(); err != nil {
+			return nil, err
+		}
+	}
+
+	cfg, err := store.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	var missing []config.Project
+	for _, p := range cfg.Projects {
+		if !fs.PathExists(p.Path) {
+			missing = append(missing, p)
+		}
+	}
+	if len(missing) == 0 {
+		return nil, nil
+	}
+
+	discovered, err := fs.DiscoverProjects(dir, depth)
+	if err != nil {
+		return nil, err
+	}
+
+	// Linked worktrees and submodules share the identity of their main
+	// repository, so only repository roots and plain directories are
+	// candidates
+	byRemote := make(map[string][]string)
+	byRoot := make(map[string][]string)
+	byName := make(map[string][]string)
+	for _, d := range discovered {
+		gitInfo := fs.CheckGitRepo(d.Path)
+		if gitInfo.HasGit && !gitInfo.IsRepoRoot() {
+			continue
+		}
+		byName[filepath.Base(d.Path)] = append(byName[filepath.Base(d.Path)], d.Path)
+		if !gitInfo.HasGit {
+			continue
+		}
+		if remote := fs.NormalizeRemote(d.Remote); remote != "" {
+			byRemote[remote] = append(byRemote[remote], d.Path)
+		}
+		if root := fs.RootCommit(d.Path); root != "" {
+			byRoot[root] = append(byRoot[root], d.Path)
+		}
+	}
+
+	changes := make(map[string]string)
+	claimed := make(map[string]string)
+	for _, p := range missing {
+		var paths []string
+		if p.GitRemote != "" {
+			paths = byRemote[fs.NormalizeRemote(p.GitRemote)]
+		}
+		if len(paths) == 0 && p.RootCommit != "" {
+			paths = byRoot[p.RootCommit]
+		}
+		// Without an identity the directory name is all there is to go by
+		if p.GitRemote == "" && p.RootCommit == "" {
+			paths = byName[filepath.Base(p.Path)]
+		}
+
+		switch {
+		case len(paths) > 1:
+			fmt.Fprintf(os.Stderr, "'%s' matches several directories, skipping it: %s\n", p.Name, strings.Join(paths, ", "))
+		case len(paths) == 1 && claimed[paths[0]] != "":
+			fmt.Fprintf(os.Stderr, "Both '%s' and '%s' match %s, skipping '%s'\n", claimed[paths[0]], p.Name, paths[0], p.Name)
+		case len(paths) == 1:
+			changes[p.Name] = paths[0]
+			claimed[paths[0]] = p.Name
+		default:
+			fmt.Fprintf(os.Stderr, "Could not find '%s' under %s\n", p.Name, dir)
+		}
+	}
+
+	return changes, nil
+}
+
+// applyRelocation shows the path changes and saves them once confirmed
+func applyRelocation(changes map[string]string, dryRun, yes bool) error {
+	if len(changes) == 0 {
+		fmt.Println("No projects to relocate")
+		return nil
+	}
+
+	cfg, err := store.Load()
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(changes))
+	for name := range changes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		project, _ := cfg.FindProject(name)
+		fmt.Printf("  %s\n    - %s\n    + %s\n", name, project.Path, changes[name])
+	}
+
+	if dryRun {
+		return nil
+	}
+	if !yes {
+		answer := prompt(bufio.NewReader(os.Stdin), fmt.Sprintf("Relocate %d projects? [y/N] ", len(changes)))
+		if !strings.EqualFold(answer, "y") && !strings.EqualFold(answer, "yes") {
+			fmt.Println("Nothing changed")
+			return nil
+		}
+	}
+
+	if err := store.SetPaths(changes); err != nil {
+		return err
+	}
+	fmt.Printf("Relocated %d projects\n", len(changes))
+	return nil
+}
@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"mpm/pkg/task"
+)
+
+// newRunCmd creates the `mpm run` command
+func newRunCmd() *cobra.Command {
+	var runCmd = &cobra.Command{
+		Use:   "run <project> [<task>] [-- <args>...]",
+		Short: "Run a project task such as build, test or dev",
+		Long: `Run a named task in the project directory, streaming its output, and exit
+with the task's exit code. Without a task, list the project's tasks.
+
+Tasks come from, in order of precedence:
+  - the "tasks" object of the project's config entry
+  - the "tasks" object of ` + task.LocalFile + ` in the project directory
+  - Makefile targets, package.json scripts, justfile recipes and Taskfile.yml tasks
+
+Arguments after -- are passed on to the task:
+
+  mpm run api test -- -run TestLogin`,
+		Args:              cobra.MinimumNArgs(1),
+		ValidArgsFunction: completeTasks,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 1 {
+				return ListTasks(args[0])
+			}
+			return RunTask(args[0], args[1], args[2:])
+		},
+	}
+
+	return runCmd
+}
+
+// ListTasks prints the tasks of a project
+func ListTasks(query string) error {
+	project, err := resolveProject(query, false)
+	if err != nil {
+		return err
+	}
+	tasks, err := task.Discover(project)
+	if err != nil {
+		return err
+	}
+
+	if len(tasks) == 0 {
+		fmt.Printf("No tasks found for '%s'\n", project.Name)
+		return nil
+	}
+
+	width := 0
+	for _, t := range tasks {
+		width = max(width, len(t.Name))
+	}
+	for _, t := range tasks {
+		fmt.Printf("  %-*s  %s  (%s)\n", width, t.Name, t.Command, t.Source)
+	}
+	return nil
+}
+
+// RunTask runs a project task attached to the terminal. A failing task is
+// returned as an *exec.ExitError so mpm exits with the same code.
+func RunTask(query, name string, args []string) error {
+	project, err := resolveProject(query, false)
+	if err != nil {
+		return err
+	}
+	tasks, err := task.Discover(project)
+	if err != nil {
+		return err
+	}
+
+	t, ok := task.Find(tasks, name)
+	if !ok {
+		if len(tasks) == 0 {
+			return fmt.Errorf("project '%s' has no tasks", project.Name)
+		}
+		return fmt.Errorf("unknown task '%s' (use %s)", name, strings.Join(task.Names(tasks), ", "))
+	}
+
+	if err := store.RecordOpen(project.Name); err != nil {
+		return err
+	}
+
+	cmd := t.Cmd(project.Path, args)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	// Ctrl+C reaches the task too; wait for it to exit instead of dying
+	// first, so its exit code is still reported
+	interrupts := make(chan os.Signal, 1)
+	signal.Notify(interrupts, os.Interrupt)
+	defer signal.Stop(interrupts)
+
+	return cmd.Run()
+}
@@ -0,0 +1,191 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"mpm/pkg/config"
+	"mpm/pkg/fs"
+	"mpm/pkg/ui"
+)
+
+// scanCandidate is a discovered project proposed for registration
+type scanCandidate struct {
+	Name     string   `json:"name"`
+	Path     string   `json:"path"`
+	Category string   `json:"category"`
+	Markers  []string `json:"markers"`
+	Remote   string   `json:"remote,omitempty"`
+}
+
+// newScanCmd creates the `mpm scan` command
+func newScanCmd() *cobra.Command {
+	var scanCmd = &cobra.Command{
+		Use:   "scan <dir>",
+		Short: "Find project repositories under a directory and register them",
+		Long: `Walk a directory tree looking for project roots (.git, go.mod, package.json,
+Cargo.toml, pyproject.toml, ...) that are not registered yet, and offer them
+in a checklist. The proposed name is the directory name; the proposed
+category is the parent folder, or the remote owner for projects sitting
+directly in <dir>.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			depth, _ := cmd.Flags().GetInt("depth")
+			yes, _ := cmd.Flags().GetBool("yes")
+			asJSON, _ := cmd.Flags().GetBool("json")
+			category, _ := cmd.Flags().GetString("category")
+			return ScanProjects(args[0], depth, category, yes, asJSON)
+		},
+	}
+
+	scanCmd.Flags().IntP("depth", "d", 4, "Maximum directory depth to search (0 for unlimited)")
+	scanCmd.Flags().BoolP("yes", "y", false, "Register every new project without asking")
+	scanCmd.Flags().Bool("json", false, "Print candidates as JSON (registers nothing unless --yes is given)")
+	scanCmd.Flags().StringP("category", "c", "", "Use this category for every project instead of the proposed one")
+
+	scanCmd.RegisterFlagCompletionFunc("category", completeCategories)
+
+	return scanCmd
+}
+
+// ScanProjects discovers unregistered projects under root and registers
+// the ones the user picks
+func ScanProjects(root string, depth int, category string, yes, asJSON bool) error {
+	root, err := config.ExpandPath(root)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := store.Load()
+	if err != nil {
+		return err
+	}
+
+	discovered, err := fs.DiscoverProjects(root, depth)
+	if err != nil {
+		return err
+	}
+
+	candidates := proposeCandidates(root, discovered, cfg)
+	if category != "" {
+		for i := range candidates {
+			candidates[i].Category = category
+		}
+	}
+
+	var selected []scanCandidate
+	switch {
+	case yes:
+		selected = candidates
+	case asJSON:
+		return printJSON(candidates)
+	case len(candidates) == 0:
+		fmt.Println("No new projects found")
+		return nil
+	default:
+		items := make([]ui.PickerItem, len(candidates))
+		for i, c := range candidates {
+			items[i] = ui.PickerItem{
+				Title:  fmt.Sprintf("%s [%s]", c.Name, c.Category),
+				Detail: c.Path,
+			}
+		}
+
+		indexes, err := ui.RunChecklist(fmt.Sprintf("%d new projects under %s", len(candidates), root), items)
+		if err != nil {
+			return err
+		}
+		for _, i := range indexes {
+			selected = append(selected, candidates[i])
+		}
+	}
+
+	if len(selected) > 0 {
+		projects := make([]config.Project, len(selected))
+		for i, c := range selected {
+			projects[i] = config.Project{Name: c.Name, Path: c.Path, Category: c.Category}
+		}
+		if err := store.AddProjects(projects); err != nil {
+			return err
+		}
+	}
+
+	if asJSON {
+		return printJSON(selected)
+	}
+
+	for _, c := range selected {
+		fmt.Printf("Added project '%s' with path '%s' and category '%s'\n", c.Name, c.Path, c.Category)
+	}
+	if len(selected) == 0 {
+		fmt.Println("No projects added")
+	}
+	return nil
+}
+
+// proposeCandidates turns discovered directories that are not registered
+// yet into candidates with a unique name and a proposed category
+func proposeCandidates(root string, discovered []fs.DiscoveredProject, cfg config.Config) []scanCandidate {
+	registered := make(map[string]bool)
+	taken := make(map[string]bool)
+	for _, p := range cfg.Projects {
+		registered[fs.CanonicalPath(p.Path)] = true
+		taken[p.Name] = true
+		for _, a := range p.Aliases {
+			taken[a] = true
+		}
+	}
+
+	candidates := []scanCandidate{}
+	for _, d := range discovered {
+		if registered[fs.CanonicalPath(d.Path)] {
+			continue
+		}
+
+		parent := filepath.Dir(d.Path)
+		category := filepath.Base(parent)
+		if parent == root || d.Path == root {
+			if owner := fs.RemoteOwner(d.Remote); owner != "" {
+				category = owner
+			}
+		}
+
+		name := uniqueName(filepath.Base(d.Path), filepath.Base(parent), taken)
+		taken[name] = true
+
+		candidates = append(candidates, scanCandidate{
+			Name:     name,
+			Path:     d.Path,
+			Category: category,
+			Markers:  d.Markers,
+			Remote:   d.Remote,
+		})
+	}
+
+	return candidates
+}
+
+// uniqueName returns name, or a parent-qualified variant if it is taken
+func uniqueName(name, parent string, taken map[string]bool) string {
+	if !taken[name] {
+		return name
+	}
+
+	qualified := parent + "-" + name
+	candidate := qualified
+	for i := 2; taken[candidate]; i++ {
+		candidate = fmt.Sprintf("%s-%d", qualified, i)
+	}
+	return candidate
+}
+
+// printJSON writes v to stdout as indented JSON
+func printJSON(v any) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
@@ -0,0 +1,156 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"mpm/pkg/config"
+	"mpm/pkg/fs"
+	"mpm/pkg/health"
+)
+
+// projectDetails is everything `mpm show` reports about a project, the
+// same information as the action view of interactive mode
+type projectDetails struct {
+	Project    config.Project       `json:"project"`
+	Missing    bool                 `json:"missing"`
+	Git        fs.GitInfo           `json:"git"`
+	TotalFiles int                  `json:"total_files"`
+	FileTypes  []fs.FileTypeCount   `json:"file_types"`
+	Health     *health.HealthStatus `json:"health,omitempty"`
+
+	files []fs.FileEntry
+}
+
+// newShowCmd creates the `mpm show` command
+func newShowCmd() *cobra.Command {
+	var showCmd = &cobra.Command{
+		Use:   "show <project>",
+		Short: "Show a project's details, git remotes, file types and health",
+		Long: `Print the details shown in the action view of interactive mode: metadata,
+git remotes, the most common file extensions and the health summary.
+With --json the same information is printed as a JSON object, e.g. for
+fzf previews or status bars.`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeProjects,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			asJSON, _ := cmd.Flags().GetBool("json")
+			return ShowProject(args[0], asJSON)
+		},
+	}
+
+	showCmd.Flags().Bool("json", false, "Print the details as JSON")
+
+	return showCmd
+}
+
+// ShowProject prints the details of a project by name or alias
+func ShowProject(name string, asJSON bool) error {
+	project, err := store.GetProject(name)
+	if err != nil {
+		return err
+	}
+
+	details := collectDetails(project)
+	if asJSON {
+		return printJSON(details)
+	}
+
+	printDetails(details)
+	return nil
+}
+
+// collectDetails scans the project directory the same way the action view
+// does: three levels deep for file types, plus git and health checks
+func collectDetails(project config.Project) projectDetails {
+	details := projectDetails{
+		Project:   project,
+		Git:       fs.GitInfo{Remotes: []fs.GitRemote{}},
+		FileTypes: []fs.FileTypeCount{},
+	}
+
+	if _, err := os.Stat(project.Path); err != nil {
+		details.Missing = true
+		return details
+	}
+
+	details.files = fs.ScanDirectory(project.Path, 3, 0, "")
+	for _, f := range details.files {
+		if !f.IsDir {
+			details.TotalFiles++
+		}
+	}
+	if counts := fs.CountFileTypes(details.files); counts != nil {
+		details.FileTypes = counts
+	}
+	details.Git = fs.CheckGitStatus(project.Path)
+
+	status := health.ScanProjectHealth(project.Path)
+	details.Health = &status
+
+	return details
+}
+
+// projectNames maps the canonical path of every registered project to its
+// name, to recognize projects among a repository's worktrees
+func projectNames() map[string]string {
+	names := make(map[string]string)
+	cfg, err := store.Load()
+	if err != nil {
+		return names
+	}
+	for _, p := range cfg.Projects {
+		names[fs.CanonicalPath(p.Path)] = p.Name
+	}
+	return names
+}
+
+// printDetails prints project details for humans
+func printDetails(d projectDetails) {
+	p := d.Project
+
+	category := p.Category
+	if category == "" {
+		category = "Uncategorized"
+	}
+	fmt.Printf("%s [%s]\n%s\n", p.Name, category, p.Path)
+
+	if len(p.Tags) > 0 {
+		fmt.Printf("Tags: %s\n", strings.Join(p.Tags, ", "))
+	}
+	if len(p.Aliases) > 0 {
+		fmt.Printf("Aliases: %s\n", strings.Join(p.Aliases, ", "))
+	}
+	if p.Description != "" {
+		fmt.Println(p.Description)
+	}
+	if !p.LastOpened.IsZero() {
+		fmt.Printf("Opened %d times, last on %s\n", p.OpenCount, p.LastOpened.Local().Format("2006-01-02 15:04"))
+	}
+	fmt.Println()
+
+	if d.Missing {
+		fmt.Println("This directory no longer exists. Run 'mpm doctor --fix' to prune or relocate it.")
+		return
+	}
+
+	fmt.Print(fs.RenderGitInfo(d.Git))
+	fmt.Print(fs.RenderWorktrees(d.Git, projectNames()))
+	if len(d.FileTypes) > 0 {
+		fmt.Print(fs.RenderFileChart(d.files, d.FileTypes))
+	}
+
+	if d.Health != nil {
+		fmt.Println()
+		for _, line := range strings.Split(strings.TrimRight(health.RenderHealthStatus(*d.Health), "\n"), "\n") {
+			if line == "" {
+				fmt.Println()
+				continue
+			}
+			fmt.Println("  " + line)
+		}
+	}
+}
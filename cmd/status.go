@@ -0,0 +1,315 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/spf13/cobra"
+
+	"mpm/pkg/config"
+	"mpm/pkg/fs"
+	"mpm/pkg/health"
+)
+
+// statusOptions selects the projects `mpm status` checks and reports
+type statusOptions struct {
+	Category string
+	Tags     []string
+	Dirty    bool
+	Unpushed bool
+	JSON     bool
+	Jobs     int
+	Timeout  time.Duration
+}
+
+// projectStatus is one row of `mpm status`
+type projectStatus struct {
+	Name   string     `json:"name"`
+	Path   string     `json:"path"`
+	Git    fs.GitInfo `json:"git"`
+	Health string     `json:"health,omitempty"` // healthy, warning or critical
+	Error  string     `json:"error,omitempty"`  // Why the repository could not be checked
+}
+
+// newStatusCmd creates the `mpm status` command
+func newStatusCmd() *cobra.Command {
+	var statusCmd = &cobra.Command{
+		Use:   "status",
+		Short: "Show branch, changes and sync state of every git project",
+		Long: `Check every registered git repository at once and print its branch,
+uncommitted files, commits ahead of and behind its upstream, the age of the
+last commit and the health indicator of the action view.
+
+Repositories are checked in parallel (--jobs) and given up on after
+--timeout, so a slow network drive cannot hold up the whole table.
+Repositories that timed out are listed as such even with --dirty or
+--unpushed, since they might match.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var opts statusOptions
+			opts.Category, _ = cmd.Flags().GetString("category")
+			opts.Tags, _ = cmd.Flags().GetStringSlice("tag")
+			opts.Dirty, _ = cmd.Flags().GetBool("dirty")
+			opts.Unpushed, _ = cmd.Flags().GetBool("unpushed")
+			opts.JSON, _ = cmd.Flags().GetBool("json")
+			opts.Jobs, _ = cmd.Flags().GetInt("jobs")
+			opts.Timeout, _ = cmd.Flags().GetDuration("timeout")
+			return ShowStatus(opts)
+		},
+	}
+
+	statusCmd.Flags().StringP("category", "c", "", "Only check projects in this category")
+	statusCmd.Flags().StringSliceP("tag", "t", nil, "Only check projects with this tag (repeatable, all must match)")
+	statusCmd.Flags().Bool("dirty", false, "Only show repositories with uncommitted changes")
+	statusCmd.Flags().Bool("unpushed", false, "Only show repositories with commits that are not pushed")
+	statusCmd.Flags().Bool("json", false, "Print the status as JSON")
+	statusCmd.Flags().IntP("jobs", "j", 8, "Number of repositories checked at the same time")
+	statusCmd.Flags().Duration("timeout", 5*time.Second, "Give up on a repository after this long")
+
+	statusCmd.RegisterFlagCompletionFunc("category", completeCategories)
+	statusCmd.RegisterFlagCompletionFunc("tag", completeTags)
+
+	return statusCmd
+}
+
+// ShowStatus checks the selected projects and prints their status
+func ShowStatus(opts statusOptions) error {
+	if opts.Jobs < 1 {
+		return fmt.Errorf("--jobs must be at least 1")
+	}
+	if opts.Timeout <= 0 {
+		return fmt.Errorf("--timeout must be positive")
+	}
+
+	cfg, err := store.Load()
+	if err != nil {
+		return err
+	}
+
+	// Missing directories are reported by `mpm doctor`
+	var projects []config.Project
+	for _, p := range filterProjects(cfg.Projects, opts.Category, opts.Tags) {
+		if fs.PathExists(p.Path) {
+			projects = append(projects, p)
+		}
+	}
+
+	var rows []projectStatus
+	for _, s := range checkProjects(projects, opts.Jobs, opts.Timeout) {
+		// A repository that timed out may match the filters, so it is
+		// always shown with its error
+		if s.Error != "" {
+			rows = append(rows, s)
+			continue
+		}
+		if !s.Git.HasGit {
+			continue
+		}
+		if opts.Dirty && !s.Git.Dirty() {
+			continue
+		}
+		if opts.Unpushed && !unpushed(s.Git) {
+			continue
+		}
+		rows = append(rows, s)
+	}
+
+	if opts.JSON {
+		if rows == nil {
+			rows = []projectStatus{}
+		}
+		return printJSON(rows)
+	}
+
+	printStatusTable(rows)
+	return nil
+}
+
+// checkProjects collects the status of every project with a bounded
+// number of workers, keeping the order of projects
+func checkProjects(projects []config.Project, jobs int, timeout time.Duration) []projectStatus {
+	results := make([]projectStatus, len(projects))
+	forEachProject(projects, jobs, func(i int, p config.Project) {
+		results[i] = checkProject(p, timeout)
+	})
+	return results
+}
+
+// forEachProject calls fn for every project from up to jobs goroutines and
+// returns once all calls are done. fn gets the index of the project, so it
+// can store its result in order.
+func forEachProject(projects []config.Project, jobs int, fn func(i int, p config.Project)) {
+	indexes := make(chan int)
+
+	var wg sync.WaitGroup
+	for range min(jobs, len(projects)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				fn(i, projects[i])
+			}
+		}()
+	}
+
+	for i := range projects {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+}
+
+// checkProject collects the git status and health of one project
+func checkProject(project config.Project, timeout time.Duration) projectStatus {
+	status := projectStatus{Name: project.Name, Path: project.Path}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	// The health scan runs git too, so it shares the deadline
+	gitInfo, err := fs.CheckGitStatusContext(ctx, project.Path)
+	status.Git = gitInfo
+	if err == nil && gitInfo.HasGit {
+		status.Health = health.ScanProjectHealthContext(ctx, project.Path).Level()
+		err = ctx.Err()
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		status.Error = fmt.Sprintf("timed out after %s", timeout)
+		status.Health = ""
+	}
+	return status
+}
+
+// unpushed reports whether a repository has commits that are not on a
+// remote: ahead of its upstream, or on a branch without one
+func unpushed(g fs.GitInfo) bool {
+	if g.Ahead > 0 {
+		return true
+	}
+	return g.Upstream == "" && !g.Detached && g.Head != ""
+}
+
+// printStatusTable prints one line per repository
+func printStatusTable(rows []projectStatus) {
+	if len(rows) == 0 {
+		fmt.Println("No repositories found")
+		return
+	}
+
+	header := []string{"PROJECT", "BRANCH", "CHANGES", "SYNC", "LAST COMMIT", "HEALTH"}
+	table := [][]string{header}
+	for _, s := range rows {
+		if s.Error != "" {
+			table = append(table, []string{s.Name, s.Error, "", "", "", ""})
+			continue
+		}
+		table = append(table, []string{
+			s.Name,
+			statusBranch(s.Git),
+			statusChanges(s.Git),
+			statusSync(s.Git),
+			statusAge(s.Git.LastCommit),
+			s.Health,
+		})
+	}
+
+	// Pad on the plain text, only the last column is colored
+	widths := make([]int, len(header))
+	for _, row := range table {
+		for i, cell := range row {
+			widths[i] = max(widths[i], lipgloss.Width(cell))
+		}
+	}
+
+	for r, row := range table {
+		var b strings.Builder
+		for i, cell := range row[:len(row)-1] {
+			b.WriteString(cell + strings.Repeat(" ", widths[i]-lipgloss.Width(cell)+2))
+		}
+
+		last := row[len(row)-1]
+		if r > 0 && last != "" {
+			last = health.LevelStyle(last).Render(last)
+		}
+		fmt.Println(strings.TrimRight(b.String()+last, " "))
+	}
+}
+
+// statusBranch names the branch, or the commit of a detached HEAD
+func statusBranch(g fs.GitInfo) string {
+	if g.Detached {
+		return "(detached " + g.Head + ")"
+	}
+	return g.Branch
+}
+
+// statusChanges counts the files with uncommitted changes
+func statusChanges(g fs.GitInfo) string {
+	if !g.Dirty() {
+		return "clean"
+	}
+
+	var parts []string
+	for _, c := range []struct {
+		count int
+		label string
+	}{
+		{g.Conflicts, "conflicted"},
+		{g.Staged, "staged"},
+		{g.Unstaged, "modified"},
+		{g.Untracked, "untracked"},
+	} {
+		if c.count > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", c.count, c.label))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// statusSync compares the branch with its upstream
+func statusSync(g fs.GitInfo) string {
+	switch {
+	case g.Detached:
+		return ""
+	case g.Head == "":
+		return "no commits"
+	case g.Upstream == "":
+		return "no upstream"
+	case g.Ahead == 0 && g.Behind == 0:
+		return "up to date"
+	}
+
+	var parts []string
+	if g.Ahead > 0 {
+		parts = append(parts, fmt.Sprintf("↑%d", g.Ahead))
+	}
+	if g.Behind > 0 {
+		parts = append(parts, fmt.Sprintf("↓%d", g.Behind))
+	}
+	return strings.Join(parts, " ")
+}
+
+// statusAge renders how long ago a commit was made, in its largest unit
+func statusAge(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+
+	d := time.Since(t)
+	switch {
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(d.Hours()))
+	case d < 30*24*time.Hour:
+		return fmt.Sprintf("%dd ago", int(d.Hours()/24))
+	case d < 365*24*time.Hour:
+		return fmt.Sprintf("%dmo ago", int(d.Hours()/24/30))
+	}
+	return fmt.Sprintf("%dy ago", int(d.Hours()/24/365))
+}
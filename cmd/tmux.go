@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"mpm/pkg/tmux"
+)
+
+// newTmuxCmd creates the `mpm tmux` command
+func newTmuxCmd() *cobra.Command {
+	var tmuxCmd = &cobra.Command{
+		Use:   "tmux <project>",
+		Short: "Attach to a project's tmux session, creating it if needed",
+		Long: `Attach to the tmux session named after a project, or switch to it when
+already inside tmux. A missing session is created in the project directory
+with the windows listed under "tmux_windows" in the project's config entry,
+or with a single shell. The project is looked up like 'mpm go' does.
+
+Projects with a running session are marked in 'mpm list' and interactive mode.`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeProjects,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			detach, _ := cmd.Flags().GetBool("detach")
+			return TmuxProject(args[0], detach)
+		},
+	}
+
+	tmuxCmd.Flags().BoolP("detach", "d", false, "Only create the session, do not attach to it")
+
+	return tmuxCmd
+}
+
+// TmuxProject brings up the tmux session of a project
+func TmuxProject(query string, detach bool) error {
+	project, err := resolveProject(query, false)
+	if err != nil {
+		return err
+	}
+
+	created, err := tmux.Ensure(project)
+	if err != nil {
+		return err
+	}
+	if err := store.RecordOpen(project.Name); err != nil {
+		return err
+	}
+
+	if detach {
+		if created {
+			fmt.Printf("Created tmux session '%s'\n", tmux.SessionName(project.Name))
+		} else {
+			fmt.Printf("tmux session '%s' is already running\n", tmux.SessionName(project.Name))
+		}
+		return nil
+	}
+
+	attach := tmux.AttachCmd(project)
+	attach.Stdin = os.Stdin
+	attach.Stdout = os.Stdout
+	attach.Stderr = os.Stderr
+	return attach.Run()
+}
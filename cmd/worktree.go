@@ -0,0 +1,381 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"mpm/pkg/config"
+	"mpm/pkg/fs"
+)
+
+// newWorktreeCmd creates the `mpm worktree` command group
+func newWorktreeCmd() *cobra.Command {
+	var worktreeCmd = &cobra.Command{
+		Use:   "worktree",
+		Short: "Create, list and prune git worktrees of projects",
+		Long: `Manage git worktrees as projects of their own. Worktrees created here are
+registered as children of their project, inherit its category, tags and
+launcher, and are shown under it in interactive mode.`,
+	}
+
+	var addCmd = &cobra.Command{
+		Use:   "add <project> <branch>",
+		Short: "Check out a branch in a new worktree and register it",
+		Long: `Check out a branch in a new worktree of a project's repository and register
+it as '<project>@<branch>'. A branch that does not exist yet is created from
+--base, or from the current HEAD.
+
+Worktrees are created in "worktree_dir" from the config file, as
+<worktree_dir>/<project>/<branch>, or next to the repository as
+<repository>-<branch>. Slashes in the branch name become dashes.`,
+		Args:              cobra.ExactArgs(2),
+		ValidArgsFunction: completeProjects,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name, _ := cmd.Flags().GetString("name")
+			base, _ := cmd.Flags().GetString("base")
+			return AddWorktree(args[0], args[1], name, base)
+		},
+	}
+
+	addCmd.Flags().String("name", "", "Project name for the worktree (default <project>@<branch>)")
+	addCmd.Flags().String("base", "", "Commit or branch a new branch starts at (default HEAD)")
+
+	var listCmd = &cobra.Command{
+		Use:               "list [<project>]",
+		Short:             "List the worktrees of every project's repository",
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: completeProjects,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			query := ""
+			if len(args) == 1 {
+				query = args[0]
+			}
+			return ListWorktrees(query)
+		},
+	}
+
+	var pruneCmd = &cobra.Command{
+		Use:   "prune [<project>]",
+		Short: "Remove stale worktrees and unregister them",
+		Long: `Remove worktrees whose directory is gone or whose branch's upstream was
+deleted (usually because its pull request was merged), and unregister their
+projects. Worktrees with uncommitted changes are kept unless --force is given.`,
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: completeProjects,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			query := ""
+			if len(args) == 1 {
+				query = args[0]
+			}
+			dryRun, _ := cmd.Flags().GetBool("dry-run")
+			yes, _ := cmd.Flags().GetBool("yes")
+			force, _ := cmd.Flags().GetBool("force")
+			return PruneWorktrees(query, dryRun, yes, force)
+		},
+	}
+
+	pruneCmd.Flags().Bool("dry-run", false, "Show what would be removed without changing anything")
+	pruneCmd.Flags().BoolP("yes", "y", false, "Remove without asking for confirmation")
+	pruneCmd.Flags().Bool("force", false, "Also remove worktrees with uncommitted changes")
+
+	worktreeCmd.AddCommand(addCmd, listCmd, pruneCmd)
+	return worktreeCmd
+}
+
+// AddWorktree creates a worktree of a project's repository for branch and
+// registers it as a child project
+func AddWorktree(query, branch, name, base string) error {
+	if strings.HasPrefix(branch, "-") || !fs.ValidBranch(branch) {
+		return fmt.Errorf("invalid branch name '%s'", branch)
+	}
+
+	cfg, err := store.Load()
+	if err != nil {
+		return err
+	}
+	parent, err := resolveProject(query, false)
+	if err != nil {
+		return err
+	}
+	// Worktrees of a worktree belong to the same parent
+	if p, ok := cfg.FindProject(parent.Parent); ok {
+		parent = p
+	}
+
+	gitInfo := fs.CheckGitRepo(parent.Path)
+	if !gitInfo.HasGit {
+		return fmt.Errorf("project '%s' is not a git repository", parent.Name)
+	}
+
+	if name == "" {
+		name = parent.Name + "@" + branch
+	}
+	if _, ok := cfg.FindProject(name); ok {
+		return fmt.Errorf("project name '%s' is already taken, choose another with --name", name)
+	}
+
+	path, err := worktreePath(cfg, parent, gitInfo.Root, branch)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("%s already exists", path)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("creating %s: %w", filepath.Dir(path), err)
+	}
+
+	if err := fs.AddWorktree(gitInfo.Root, path, branch, base); err != nil {
+		return err
+	}
+
+	err = store.AddProjects([]config.Project{{
+		Name:        name,
+		Path:        path,
+		Category:    parent.Category,
+		Tags:        parent.Tags,
+		Launcher:    parent.Launcher,
+		TmuxWindows: parent.TmuxWindows,
+		Tasks:       parent.Tasks,
+		Parent:      parent.Name,
+	}})
+	if err != nil {
+		return fmt.Errorf("worktree created at %s but not registered: %w", path, err)
+	}
+
+	fmt.Printf("Created worktree '%s' at %s\n", name, path)
+	return nil
+}
+
+// worktreePath returns the directory for a new worktree of parent
+func worktreePath(cfg config.Config, parent config.Project, root, branch string) (string, error) {
+	dirName := strings.ReplaceAll(branch, "/", "-")
+
+	if cfg.WorktreeDir != "" {
+		dir, err := config.ExpandPath(cfg.WorktreeDir)
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(dir, parent.Name, dirName), nil
+	}
+	return filepath.Join(filepath.Dir(root), filepath.Base(root)+"-"+dirName), nil
+}
+
+// repository is a git repository shared by one or more projects
+type repository struct {
+	root      string // Main working tree, or the first project found in it
+	worktrees []fs.GitWorktree
+}
+
+// projectRepositories returns the repositories of the given projects, each
+// once however many of its worktrees are registered
+func projectRepositories(projects []config.Project) []repository {
+	var repos []repository
+	seen := make(map[string]bool)
+	for _, p := range projects {
+		if !fs.PathExists(p.Path) {
+			continue
+		}
+		gitInfo := fs.CheckGitRepo(p.Path)
+		if !gitInfo.HasGit || seen[gitInfo.CommonDir] {
+			continue
+		}
+		seen[gitInfo.CommonDir] = true
+
+		repo := repository{root: gitInfo.Root, worktrees: fs.ListWorktrees(p.Path)}
+		if len(repo.worktrees) > 0 && !repo.worktrees[0].Bare {
+			repo.root = repo.worktrees[0].Path
+		}
+		repos = append(repos, repo)
+	}
+	return repos
+}
+
+// worktreeProjects returns the projects whose repositories to look at: the
+// one matching query, or all of them
+func worktreeProjects(query string) ([]config.Project, error) {
+	cfg, err := store.Load()
+	if err != nil {
+		return nil, err
+	}
+	if query == "" {
+		return cfg.Projects, nil
+	}
+
+	project, err := resolveProject(query, false)
+	if err != nil {
+		return nil, err
+	}
+	return []config.Project{project}, nil
+}
+
+// ListWorktrees prints the worktrees of every repository with more than
+// one, or of the given project's repository
+func ListWorktrees(query string) error {
+	projects, err := worktreeProjects(query)
+	if err != nil {
+		return err
+	}
+	names := projectNames()
+
+	printed := 0
+	for _, repo := range projectRepositories(projects) {
+		if query == "" && len(repo.worktrees) < 2 {
+			continue
+		}
+
+		header := repo.root
+		if name, ok := names[fs.CanonicalPath(repo.root)]; ok {
+			header = fmt.Sprintf("%s (%s)", name, repo.root)
+		}
+		fmt.Printf("\n[%s]\n", header)
+
+		refWidth, pathWidth := 0, 0
+		for _, w := range repo.worktrees {
+			refWidth = max(refWidth, len(worktreeRef(w)))
+			pathWidth = max(pathWidth, len(w.Path))
+		}
+		for _, w := range repo.worktrees {
+			line := fmt.Sprintf("  %-*s  %s", refWidth, worktreeRef(w), w.Path)
+			if name, ok := names[fs.CanonicalPath(w.Path)]; ok {
+				line = fmt.Sprintf("  %-*s  %-*s  [%s]", refWidth, worktreeRef(w), pathWidth, w.Path, name)
+			}
+			if w.Prunable {
+				line += "  (missing, prunable)"
+			}
+			fmt.Println(line)
+		}
+		printed++
+	}
+
+	if printed == 0 {
+		fmt.Println("No worktrees found")
+	}
+	return nil
+}
+
+// worktreeRef describes what a worktree has checked out
+func worktreeRef(w fs.GitWorktree) string {
+	switch {
+	case w.Bare:
+		return "(bare)"
+	case w.Detached:
+		return "(detached " + w.Head + ")"
+	}
+	return w.Branch
+}
+
+// staleWorktree is a worktree `mpm worktree prune` removes
+type staleWorktree struct {
+	repo    string // Main working tree the git commands run in
+	path    string
+	project string // Registered project, if any
+	reason  string
+	exists  bool // Whether the directory still has to be removed
+}
+
+// PruneWorktrees removes stale worktrees and unregisters their projects
+func PruneWorktrees(query string, dryRun, yes, force bool) error {
+	projects, err := worktreeProjects(query)
+	if err != nil {
+		return err
+	}
+	names := projectNames()
+
+	var stale []staleWorktree
+	kept := 0
+	for _, repo := range projectRepositories(projects) {
+		gone := fs.GoneBranches(repo.root)
+
+		// The main working tree is never stale
+		for i, w := range repo.worktrees {
+			if i == 0 || w.Bare {
+				continue
+			}
+
+			s := staleWorktree{repo: repo.root, path: w.Path, project: names[fs.CanonicalPath(w.Path)], exists: true}
+			switch {
+			case w.Prunable:
+				s.reason, s.exists = "directory is gone", false
+			case w.Branch != "" && gone[w.Branch]:
+				s.reason = "upstream branch '" + w.Branch + "' was deleted"
+			default:
+				continue
+			}
+
+			if s.exists && !force {
+				if status := fs.CheckGitStatus(w.Path); status.Dirty() {
+					fmt.Printf("  keeping %s: it has uncommitted changes (use --force to remove it anyway)\n", w.Path)
+					kept++
+					continue
+				}
+			}
+			stale = append(stale, s)
+		}
+	}
+
+	// Worktree projects whose directory and git record are both gone
+	listed := make(map[string]bool)
+	for _, s := range stale {
+		listed[s.project] = true
+	}
+	for _, p := range projects {
+		if p.Parent != "" && !listed[p.Name] && !fs.PathExists(p.Path) {
+			stale = append(stale, staleWorktree{path: p.Path, project: p.Name, reason: "directory is gone"})
+		}
+	}
+
+	if len(stale) == 0 {
+		if kept == 0 {
+			fmt.Println("No stale worktrees found")
+		}
+		return nil
+	}
+
+	for _, s := range stale {
+		name := s.path
+		if s.project != "" {
+			name = fmt.Sprintf("%s (%s)", s.project, s.path)
+		}
+		fmt.Printf("  %s: %s\n", name, s.reason)
+	}
+
+	if dryRun {
+		return nil
+	}
+	if !yes {
+		answer := prompt(bufio.NewReader(os.Stdin), fmt.Sprintf("Remove %d worktrees? [y/N] ", len(stale)))
+		if !strings.EqualFold(answer, "y") && !strings.EqualFold(answer, "yes") {
+			fmt.Println("Nothing changed")
+			return nil
+		}
+	}
+
+	pruned := make(map[string]bool)
+	for _, s := range stale {
+		if s.exists {
+			if err := fs.RemoveWorktree(s.repo, s.path, force); err != nil {
+				return err
+			}
+		} else if s.repo != "" && !pruned[s.repo] {
+			if err := fs.PruneWorktrees(s.repo); err != nil {
+				return err
+			}
+			pruned[s.repo] = true
+		}
+
+		if s.project != "" {
+			if err := store.RemoveProject(s.project); err != nil {
+				return err
+			}
+		}
+	}
+
+	fmt.Printf("Removed %d worktrees\n", len(stale))
+	return nil
+}
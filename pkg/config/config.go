@@ -2,150 +2,359 @@ package config
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
+	"text/template"
+	"time"
 )
 
-var (
-	configDir  string
-	configFile string
-)
+// ErrProjectNotFound is returned when a project name is not registered
+var ErrProjectNotFound = errors.New("project not found")
 
 // Project represents a managed project in the application
 type Project struct {
-	Name     string `json:"name"`
-	Path     string `json:"path"`
-	Category string `json:"category"`
+	Name         string            `json:"name"`
+	Path         string            `json:"path"`
+	Category     string            `json:"category"`
+	Tags         []string          `json:"tags,omitempty"`
+	Description  string            `json:"description,omitempty"`
+	Aliases      []string          `json:"aliases,omitempty"`
+	Launcher     string            `json:"launcher,omitempty"`     // Preferred launcher for `mpm open` and Enter in the action view
+	TmuxWindows  []TmuxWindow      `json:"tmux_windows,omitempty"` // Windows created with the project's tmux session
+	Tasks        map[string]string `json:"tasks,omitempty"`        // Commands run by `mpm run`, by task name
+	Parent       string            `json:"parent,omitempty"`       // Project this one is a git worktree of
+	GitRemote    string            `json:"git_remote,omitempty"`   // Remembered to find the repo after a move
+	RootCommit   string            `json:"root_commit,omitempty"`  // Remembered to find the repo after a move
+	CreatedAt    time.Time         `json:"created_at,omitzero"`
+	LastOpened   time.Time         `json:"last_opened,omitzero"`
+	OpenCount    int               `json:"open_count,omitempty"`
+	LastModified time.Time         `json:"last_modified,omitzero"`
+}
+
+// TmuxWindow is a window of a project's tmux session
+type TmuxWindow struct {
+	Name    string `json:"name"`
+	Command string `json:"command,omitempty"` // Typed into the window's shell, e.g. "nvim ."
+}
+
+// HasTag reports whether the project carries the given tag
+func (p Project) HasTag(tag string) bool {
+	for _, t := range p.Tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// HasAlias reports whether the project can be referred to by alias
+func (p Project) HasAlias(alias string) bool {
+	for _, a := range p.Aliases {
+		if a == alias {
+			return true
+		}
+	}
+	return false
+}
+
+// Launcher is a launcher entry from the config file. Entries named like a
+// built-in launcher override only the fields they set; other entries add
+// new launchers.
+type Launcher struct {
+	Name     string   `json:"name"`
+	Key      string   `json:"key,omitempty"`      // Key in the action view of interactive mode
+	Label    string   `json:"label,omitempty"`    // Shown as "Open in <label>"
+	Command  []string `json:"command,omitempty"`  // Program and arguments, as text/templates over Project
+	Mode     string   `json:"mode,omitempty"`     // detach, foreground or shell
+	Disabled bool     `json:"disabled,omitempty"` // Removes a built-in launcher
 }
 
+// LauncherModes are the accepted values of Launcher.Mode
+var LauncherModes = []string{"detach", "foreground", "shell"}
+
 // Config holds the application configuration
 type Config struct {
-	Projects []Project `json:"projects"`
+	Version           int               `json:"version"`
+	Launchers         []Launcher        `json:"launchers,omitempty"`
+	CategoryLaunchers map[string]string `json:"category_launchers,omitempty"` // Preferred launcher by category
+	WorktreeDir       string            `json:"worktree_dir,omitempty"`       // Where `mpm worktree add` creates worktrees
+	Projects          []Project         `json:"projects"`
 }
 
-// InitConfig initializes the config directory and file
-func InitConfig() {
-	home, err := os.UserHomeDir()
-	if err != nil {
-		fmt.Println("Error getting home directory:", err)
-		os.Exit(1)
+// FindProject returns the project with the given name or alias.
+// Names take precedence over aliases.
+func (c Config) FindProject(name string) (Project, bool) {
+	if i := c.indexOf(name); i >= 0 {
+		return c.Projects[i], true
+	}
+	return Project{}, false
+}
+
+// indexOf returns the index of the project with the given name or alias, or -1
+func (c Config) indexOf(name string) int {
+	for i, p := range c.Projects {
+		if p.Name == name {
+			return i
+		}
 	}
+	for i, p := range c.Projects {
+		if p.HasAlias(name) {
+			return i
+		}
+	}
+	return -1
+}
 
-	configDir = filepath.Join(home, ".mpm")
-	configFile = filepath.Join(configDir, "config.json")
+// validate checks invariants that must hold before the config is saved
+func (c Config) validate() error {
+	owners := make(map[string]string)
+	for _, p := range c.Projects {
+		if strings.TrimSpace(p.Name) == "" {
+			return fmt.Errorf("project name cannot be empty")
+		}
+		if _, ok := owners[p.Name]; ok {
+			return fmt.Errorf("project name '%s' is already taken", p.Name)
+		}
+		owners[p.Name] = p.Name
+	}
 
-	if _, err := os.Stat(configDir); os.IsNotExist(err) {
-		if err := os.MkdirAll(configDir, 0755); err != nil {
-			fmt.Println("Error creating config directory:", err)
-			os.Exit(1)
+	for _, p := range c.Projects {
+		for _, alias := range p.Aliases {
+			if alias == "" {
+				return fmt.Errorf("project '%s' has an empty alias", p.Name)
+			}
+			if owner, ok := owners[alias]; ok && owner != p.Name {
+				return fmt.Errorf("alias '%s' conflicts between projects '%s' and '%s'", alias, owner, p.Name)
+			}
+			owners[alias] = p.Name
 		}
 	}
 
-	if _, err := os.Stat(configFile); os.IsNotExist(err) {
-		config := Config{Projects: []Project{}}
-		SaveConfig(config)
+	launchers := make(map[string]bool)
+	for _, l := range c.Launchers {
+		if strings.TrimSpace(l.Name) == "" {
+			return fmt.Errorf("launcher name cannot be empty")
+		}
+		if launchers[l.Name] {
+			return fmt.Errorf("launcher '%s' is defined twice", l.Name)
+		}
+		launchers[l.Name] = true
+
+		if l.Mode != "" && !slices.Contains(LauncherModes, l.Mode) {
+			return fmt.Errorf("launcher '%s' has invalid mode '%s' (use %s)", l.Name, l.Mode, strings.Join(LauncherModes, ", "))
+		}
+		for _, arg := range l.Command {
+			if _, err := template.New(l.Name).Parse(arg); err != nil {
+				return fmt.Errorf("launcher '%s' has an invalid command: %w", l.Name, err)
+			}
+		}
 	}
+
+	return nil
 }
 
-// LoadConfig loads the configuration from the file
-func LoadConfig() Config {
-	data, err := os.ReadFile(configFile)
+// InitConfig initializes the config directory and file and returns a store
+// for it. A non-empty override takes precedence over MPM_CONFIG and the
+// XDG default location.
+func InitConfig(override string) (*Store, error) {
+	configFile, err := ResolveConfigPath(override)
 	if err != nil {
-		fmt.Println("Error reading config file:", err)
-		return Config{Projects: []Project{}}
+		return nil, err
 	}
 
-	var config Config
-	if err := json.Unmarshal(data, &config); err != nil {
-		fmt.Println("Error parsing config file:", err)
-		return Config{Projects: []Project{}}
+	if err := os.MkdirAll(filepath.Dir(configFile), 0755); err != nil {
+		return nil, fmt.Errorf("creating config directory: %w", err)
+	}
+
+	store := NewStore(configFile)
+	if err := store.ensureExists(); err != nil {
+		return nil, err
 	}
 
-	return config
+	return store, nil
 }
 
-// SaveConfig saves the configuration to the file
-func SaveConfig(config Config) {
-	data, err := json.MarshalIndent(config, "", "  ")
+// ResolveConfigPath determines which config file to use: the override if
+// given, then $MPM_CONFIG, then $XDG_CONFIG_HOME/mpm/config.json
+func ResolveConfigPath(override string) (string, error) {
+	if override == "" {
+		override = os.Getenv("MPM_CONFIG")
+	}
+	if override != "" {
+		return ExpandPath(override)
+	}
+
+	dir, err := ConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "config.json"), nil
+}
+
+// ConfigDir returns the default config directory, $XDG_CONFIG_HOME/mpm,
+// falling back to ~/.config/mpm
+func ConfigDir() (string, error) {
+	return xdgDir("XDG_CONFIG_HOME", ".config")
+}
+
+// CacheDir returns the directory for regenerable data such as health scans
+// and indexes, $XDG_CACHE_HOME/mpm, falling back to ~/.cache/mpm.
+// The directory is created if needed.
+func CacheDir() (string, error) {
+	dir, err := xdgDir("XDG_CACHE_HOME", ".cache")
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("creating cache directory: %w", err)
+	}
+
+	return dir, nil
+}
+
+// xdgDir resolves an XDG base directory for mpm. Relative values are
+// ignored as required by the XDG spec.
+func xdgDir(envVar, fallback string) (string, error) {
+	if base := os.Getenv(envVar); base != "" && filepath.IsAbs(base) {
+		return filepath.Join(base, "mpm"), nil
+	}
+
+	home, err := os.UserHomeDir()
 	if err != nil {
-		fmt.Println("Error encoding config:", err)
-		return
+		return "", fmt.Errorf("getting home directory: %w", err)
 	}
 
-	if err := os.WriteFile(configFile, data, 0644); err != nil {
-		fmt.Println("Error writing config file:", err)
+	return filepath.Join(home, fallback, "mpm"), nil
+}
+
+// LegacyConfigPath returns the pre-XDG config location, ~/.mpm/config.json
+func LegacyConfigPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("getting home directory: %w", err)
 	}
+
+	return filepath.Join(home, ".mpm", "config.json"), nil
 }
 
-// AddProject adds or updates a project in the configuration
-func AddProject(name, path, category string) {
-	config := LoadConfig()
+// MigrateLegacyConfig moves ~/.mpm/config.json to the XDG location when
+// only the legacy file exists. It returns the new path if a move happened.
+func MigrateLegacyConfig() (string, error) {
+	legacy, err := LegacyConfigPath()
+	if err != nil {
+		return "", err
+	}
+	if _, err := os.Stat(legacy); err != nil {
+		return "", nil
+	}
+
+	dir, err := ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	target := filepath.Join(dir, "config.json")
+	if _, err := os.Stat(target); err == nil {
+		return "", nil
+	}
+
+	data, err := os.ReadFile(legacy)
+	if err != nil {
+		return "", fmt.Errorf("reading legacy config: %w", err)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("creating config directory: %w", err)
+	}
+
+	// Copy rather than rename so moving across filesystems works too
+	if err := writeFileAtomic(target, data, 0644); err != nil {
+		return "", err
+	}
+	if err := os.Remove(legacy); err != nil {
+		return "", fmt.Errorf("removing legacy config: %w", err)
+	}
+
+	// Drop the lock file and the directory if nothing else is left in it
+	os.Remove(legacy + ".lock")
+	os.Remove(filepath.Dir(legacy))
+
+	return target, nil
+}
 
-	// Expand tilde to home directory if needed
+// ExpandPath expands a leading tilde and converts the path to an absolute path
+func ExpandPath(path string) (string, error) {
 	if strings.HasPrefix(path, "~") {
 		home, err := os.UserHomeDir()
 		if err != nil {
-			fmt.Println("Error getting home directory:", err)
-			os.Exit(1)
+			return "", fmt.Errorf("getting home directory: %w", err)
 		}
 		path = filepath.Join(home, path[1:])
 	}
 
-	// Convert to absolute path
 	absPath, err := filepath.Abs(path)
 	if err != nil {
-		fmt.Println("Error getting absolute path:", err)
-		os.Exit(1)
+		return "", fmt.Errorf("getting absolute path: %w", err)
 	}
 
-	// Check if the project already exists
-	for i, p := range config.Projects {
-		if p.Name == name {
-			config.Projects[i] = Project{Name: name, Path: absPath, Category: category}
-			SaveConfig(config)
-			fmt.Printf("Updated project '%s' with path '%s' and category '%s'\n", name, absPath, category)
-			return
-		}
+	return absPath, nil
+}
+
+// decodeConfig parses the raw config file contents, upgrading older
+// schema versions in memory first
+func decodeConfig(data []byte) (Config, MigrationReport, error) {
+	report, err := migrateData(data)
+	if err != nil {
+		return Config{}, report, err
 	}
 
-	// Add new project
-	config.Projects = append(config.Projects, Project{Name: name, Path: absPath, Category: category})
-	SaveConfig(config)
-	fmt.Printf("Added project '%s' with path '%s' and category '%s'\n", name, absPath, category)
+	var config Config
+	if err := json.Unmarshal(report.After, &config); err != nil {
+		return Config{}, report, fmt.Errorf("parsing config file: %w", err)
+	}
+
+	if config.Projects == nil {
+		config.Projects = []Project{}
+	}
+
+	return config, report, nil
 }
 
-// RemoveProject removes a project from the configuration
-func RemoveProject(name string) {
-	config := LoadConfig()
-	found := false
+// encodeConfig serializes the config for writing to disk
+func encodeConfig(config Config) ([]byte, error) {
+	config.Version = CurrentVersion
 
-	for i, p := range config.Projects {
-		if p.Name == name {
-			config.Projects = append(config.Projects[:i], config.Projects[i+1:]...)
-			found = true
-			break
-		}
+	// The open history is kept in the cache directory
+	projects := slices.Clone(config.Projects)
+	for i := range projects {
+		projects[i].OpenCount = 0
+		projects[i].LastOpened = time.Time{}
 	}
+	config.Projects = projects
 
-	if found {
-		SaveConfig(config)
-		fmt.Printf("Removed project '%s'\n", name)
-	} else {
-		fmt.Printf("Project '%s' not found\n", name)
+	data, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("encoding config: %w", err)
 	}
+	return data, nil
 }
 
-// GoToProject returns the path of a project for navigation
-func GoToProject(name string) string {
-	config := LoadConfig()
-
-	for _, p := range config.Projects {
-		if p.Name == name {
-			return p.Path
+// NormalizeList trims entries, drops empty ones and removes duplicates
+// while keeping the original order
+func NormalizeList(values []string) []string {
+	var result []string
+	seen := make(map[string]bool)
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if v == "" || seen[v] {
+			continue
 		}
+		seen[v] = true
+		result = append(result, v)
 	}
-
-	return ""
+	return result
 }
@@ -0,0 +1,126 @@
+package config
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// RankedProject is a project together with its frecency score
+type RankedProject struct {
+	Project Project
+	Score   float64
+	Match   int // How well the query matched, higher is better
+}
+
+// Frecency scores a project by how often and how recently it was opened,
+// using the same recency buckets as zoxide
+func (p Project) Frecency(now time.Time) float64 {
+	if p.OpenCount == 0 || p.LastOpened.IsZero() {
+		return 0
+	}
+
+	count := float64(p.OpenCount)
+	age := now.Sub(p.LastOpened)
+	switch {
+	case age < time.Hour:
+		return count * 4
+	case age < 24*time.Hour:
+		return count * 2
+	case age < 7*24*time.Hour:
+		return count / 2
+	default:
+		return count / 4
+	}
+}
+
+// RecordOpen bumps the open count and last-opened time of a project in the
+// open history, leaving the config file alone
+func (s *Store) RecordOpen(name string) error {
+	project, err := s.GetProject(name)
+	if err != nil {
+		return err
+	}
+
+	return s.updateHistory(func(h History) {
+		record := h[project.Name]
+		record.Count++
+		record.LastOpened = time.Now()
+		h[project.Name] = record
+	})
+}
+
+// RankProjects returns the projects whose name, alias or path contains
+// every whitespace-separated keyword of query (case-insensitive), ordered
+// by frecency, then by how well the last keyword matches. The last keyword
+// must also occur in the name, an alias or the final path component, so
+// `pay` finds ~/code/payments but not ~/pay/other. An empty query ranks
+// all projects.
+func RankProjects(projects []Project, query string, now time.Time) []RankedProject {
+	keywords := strings.Fields(strings.ToLower(query))
+
+	var ranked []RankedProject
+	for _, p := range projects {
+		if !matchesKeywords(p, keywords) {
+			continue
+		}
+		ranked = append(ranked, RankedProject{Project: p, Score: p.Frecency(now), Match: keywordMatch(p, keywords)})
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if ranked[i].Score != ranked[j].Score {
+			return ranked[i].Score > ranked[j].Score
+		}
+		if ranked[i].Match != ranked[j].Match {
+			return ranked[i].Match > ranked[j].Match
+		}
+		return ranked[i].Project.Name < ranked[j].Project.Name
+	})
+
+	return ranked
+}
+
+// matchesKeywords reports whether all keywords occur in the project's
+// name, aliases or path, with the last one matching the name, an alias
+// or the base of the path
+func matchesKeywords(p Project, keywords []string) bool {
+	if len(keywords) == 0 {
+		return true
+	}
+
+	haystack := strings.ToLower(p.Name + " " + strings.Join(p.Aliases, " ") + " " + p.Path)
+	for _, k := range keywords {
+		if !strings.Contains(haystack, k) {
+			return false
+		}
+	}
+
+	last := keywords[len(keywords)-1]
+	tail := strings.ToLower(p.Name + " " + strings.Join(p.Aliases, " ") + " " + filepath.Base(p.Path))
+	return strings.Contains(tail, last)
+}
+
+// keywordMatch rates how well the last keyword matches a project: its whole
+// name or an alias beats a prefix of one, which beats a substring of one,
+// which beats a match in the directory name only
+func keywordMatch(p Project, keywords []string) int {
+	if len(keywords) == 0 {
+		return 0
+	}
+
+	last := keywords[len(keywords)-1]
+	best := 0
+	for _, name := range append([]string{p.Name}, p.Aliases...) {
+		name = strings.ToLower(name)
+		switch {
+		case name == last:
+			return 3
+		case strings.HasPrefix(name, last):
+			best = max(best, 2)
+		case strings.Contains(name, last):
+			best = max(best, 1)
+		}
+	}
+	return best
+}
@@ -0,0 +1,179 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// OpenRecord is how often and when a project was last opened
+type OpenRecord struct {
+	Count      int       `json:"count"`
+	LastOpened time.Time `json:"last_opened"`
+}
+
+// History is the open history behind frecency ranking, keyed by project
+// name. It lives in the cache directory rather than in the config, so
+// opening a project never rewrites the config file.
+type History map[string]OpenRecord
+
+// historyPath returns the open history file of the config. Every config
+// file has its own, named after a hash of its path.
+func (s *Store) historyPath() (string, error) {
+	dir, err := CacheDir()
+	if err != nil {
+		return "", err
+	}
+
+	path, err := filepath.Abs(s.path)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(path))
+
+	return filepath.Join(dir, "frecency-"+hex.EncodeToString(sum[:6])+".json"), nil
+}
+
+// loadHistory reads the open history under a shared lock
+func (s *Store) loadHistory() (History, error) {
+	path, err := s.historyPath()
+	if err != nil {
+		return nil, err
+	}
+
+	unlock, err := lockFile(path+".lock", false)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	return readHistory(path)
+}
+
+// updateHistory applies fn to the open history while holding an exclusive
+// lock on it, and saves the result
+func (s *Store) updateHistory(fn func(History)) error {
+	path, err := s.historyPath()
+	if err != nil {
+		return err
+	}
+
+	unlock, err := lockFile(path+".lock", true)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	history, err := readHistory(path)
+	if err != nil {
+		return err
+	}
+	fn(history)
+
+	data, err := json.MarshalIndent(history, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding open history: %w", err)
+	}
+	return writeFileAtomic(path, data, 0600)
+}
+
+// readHistory loads the open history file. A missing or unreadable file is
+// an empty history: it only affects ranking and builds up again.
+func readHistory(path string) (History, error) {
+	history := make(History)
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return history, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading open history: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &history); err != nil {
+		return make(History), nil
+	}
+	return history, nil
+}
+
+// apply fills in the open count and last-opened time of the projects
+func (h History) apply(projects []Project) {
+	for i, p := range projects {
+		if record, ok := h[p.Name]; ok {
+			projects[i].OpenCount = record.Count
+			projects[i].LastOpened = record.LastOpened
+		}
+	}
+}
+
+// rename moves the history of a renamed project to its new name
+func (h History) rename(oldName, newName string) {
+	if record, ok := h[oldName]; ok {
+		delete(h, oldName)
+		h[newName] = record
+	}
+}
+
+// merge adds the history of the named projects to keep's and drops theirs
+func (h History) merge(keep string, others []string) {
+	target := h[keep]
+	for _, name := range others {
+		record, ok := h[name]
+		if !ok {
+			continue
+		}
+		target.Count += record.Count
+		if record.LastOpened.After(target.LastOpened) {
+			target.LastOpened = record.LastOpened
+		}
+		delete(h, name)
+	}
+	if target.Count > 0 {
+		h[keep] = target
+	}
+}
+
+// legacyHistory extracts the open history that older schemas kept in the
+// config file itself
+func legacyHistory(data []byte) History {
+	var doc struct {
+		Projects []struct {
+			Name       string    `json:"name"`
+			OpenCount  int       `json:"open_count"`
+			LastOpened time.Time `json:"last_opened"`
+		} `json:"projects"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil
+	}
+
+	history := make(History)
+	for _, p := range doc.Projects {
+		if p.OpenCount > 0 || !p.LastOpened.IsZero() {
+			history[p.Name] = OpenRecord{Count: p.OpenCount, LastOpened: p.LastOpened}
+		}
+	}
+	return history
+}
+
+// keepLegacyHistory moves the open history out of a config file written
+// by an older schema before it is rewritten without it. Entries already in
+// the history file are newer and win.
+func (s *Store) keepLegacyHistory(data []byte) error {
+	legacy := legacyHistory(data)
+	if len(legacy) == 0 {
+		return nil
+	}
+
+	return s.updateHistory(func(h History) {
+		for name, record := range legacy {
+			if _, ok := h[name]; !ok {
+				h[name] = record
+			}
+		}
+	})
+}
@@ -0,0 +1,101 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRecordOpenLeavesConfigAlone(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	s := NewStore(path)
+	if err := addProjectNamed(s, "api"); err != nil {
+		t.Fatal(err)
+	}
+	if err := addProjectNamed(s, "web"); err != nil {
+		t.Fatal(err)
+	}
+	before, _ := os.ReadFile(path)
+
+	for range 3 {
+		if err := s.RecordOpen("web"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.RecordOpen("gone"); err == nil {
+		t.Error("recorded an open of an unknown project")
+	}
+
+	after, _ := os.ReadFile(path)
+	if !bytes.Equal(before, after) {
+		t.Errorf("config file changed:\n%s\n---\n%s", before, after)
+	}
+
+	config, err := s.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ranked := RankProjects(config.Projects, "", time.Now())
+	if ranked[0].Project.Name != "web" || ranked[0].Project.OpenCount != 3 {
+		t.Errorf("web was not ranked first with 3 opens: %+v", ranked)
+	}
+}
+
+func TestHistoryFollowsRenameAndMerge(t *testing.T) {
+	s := NewStore(filepath.Join(t.TempDir(), "config.json"))
+	for _, name := range []string{"api", "api-old", "web"} {
+		if err := addProjectNamed(s, name); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.RecordOpen(name); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := s.RenameProject("web", "site"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.MergeProjects("api", []string{"api-old"}); err != nil {
+		t.Fatal(err)
+	}
+
+	config, err := s.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range map[string]int{"site": 1, "api": 2} {
+		if p, _ := config.FindProject(name); p.OpenCount != want {
+			t.Errorf("%s opened %d times, want %d", name, p.OpenCount, want)
+		}
+	}
+}
+
+func TestMigrateMovesOpenHistory(t *testing.T) {
+	v3 := `{"version": 3, "projects": [
+  {"name": "api", "path": "/code/api", "open_count": 7, "last_opened": "2026-01-02T03:04:05Z"},
+  {"name": "web", "path": "/code/web"}
+]}`
+	path := writeConfig(t, v3)
+	s := NewStore(path)
+
+	if _, err := s.Migrate(false); err != nil {
+		t.Fatal(err)
+	}
+
+	data, _ := os.ReadFile(path)
+	if strings.Contains(string(data), "open_count") || strings.Contains(string(data), "last_opened") {
+		t.Errorf("open history left in the config:\n%s", data)
+	}
+
+	api, err := s.GetProject("api")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	if api.OpenCount != 7 || !api.LastOpened.Equal(want) {
+		t.Errorf("got %d opens, last %v; want 7, last %v", api.OpenCount, api.LastOpened, want)
+	}
+}
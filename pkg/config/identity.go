@@ -0,0 +1,58 @@
+package config
+
+import "mpm/pkg/fs"
+
+// rememberIdentity records the git remote and first commit of the project's
+// repository so it can be found again after its directory is moved. Only a
+// repository's main working tree has an identity of its own. A missing
+// directory keeps the identity remembered before it went away.
+func (p *Project) rememberIdentity() {
+	if !fs.PathExists(p.Path) {
+		return
+	}
+
+	p.GitRemote, p.RootCommit = "", ""
+	if gitInfo := fs.CheckGitRepo(p.Path); gitInfo.IsRepoRoot() {
+		p.GitRemote = fs.PrimaryRemoteURL(gitInfo)
+		p.RootCommit = fs.RootCommit(p.Path)
+	}
+}
+
+// hasIdentity reports whether a git identity is remembered for the project
+func (p Project) hasIdentity() bool {
+	return p.GitRemote != "" || p.RootCommit != ""
+}
+
+// RememberIdentities records the git identity of every project whose
+// directory still exists but has none remembered yet, e.g. because it was
+// not a repository when it was added
+func (s *Store) RememberIdentities() error {
+	config, err := s.Load()
+	if err != nil {
+		return err
+	}
+
+	// Ask git before taking the lock, it can be slow on many projects
+	identities := make(map[string]Project)
+	for _, p := range config.Projects {
+		if p.hasIdentity() {
+			continue
+		}
+		if p.rememberIdentity(); p.hasIdentity() {
+			identities[p.Name] = p
+		}
+	}
+	if len(identities) == 0 {
+		return nil
+	}
+
+	return s.Update(func(c *Config) error {
+		for i, p := range c.Projects {
+			if withID, ok := identities[p.Name]; ok && !p.hasIdentity() && p.Path == withID.Path {
+				c.Projects[i].GitRemote = withID.GitRemote
+				c.Projects[i].RootCommit = withID.RootCommit
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,8 @@
+//go:build !unix && !windows
+
+package config
+
+// lockFile is a no-op on platforms without advisory file locking
+func lockFile(path string, exclusive bool) (func(), error) {
+	return func() {}, nil
+}
@@ -0,0 +1,73 @@
+package config
+
+import (
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/sahilm/fuzzy"
+)
+
+// MatchProjects returns the candidates for a navigation query. Projects
+// whose name, alias or path contain the query keywords are preferred;
+// only when none do is the query fuzzy-matched against names instead.
+// Candidates are ordered by frecency, then by match quality.
+func MatchProjects(projects []Project, query string, now time.Time) []RankedProject {
+	if ranked := RankProjects(projects, query, now); len(ranked) > 0 {
+		return ranked
+	}
+
+	return FuzzyRankProjects(projects, query, now)
+}
+
+// UniqueBest reports whether the first candidate clearly wins: it is the
+// only one, or it beats the runner-up on frecency or, with equal frecency,
+// on match quality
+func UniqueBest(ranked []RankedProject) bool {
+	switch len(ranked) {
+	case 0:
+		return false
+	case 1:
+		return true
+	}
+
+	first, second := ranked[0], ranked[1]
+	if first.Score != second.Score {
+		return first.Score > second.Score
+	}
+	return first.Match > second.Match
+}
+
+// FuzzyRankProjects fuzzy-matches query against each project's name,
+// aliases and directory name, keeping the best match per project. The
+// full path is left out: every project shares its parent directories, so
+// short queries would match them all.
+func FuzzyRankProjects(projects []Project, query string, now time.Time) []RankedProject {
+	var ranked []RankedProject
+	for _, p := range projects {
+		fields := append([]string{p.Name, filepath.Base(p.Path)}, p.Aliases...)
+
+		matches := fuzzy.Find(query, fields)
+		if len(matches) == 0 {
+			continue
+		}
+
+		ranked = append(ranked, RankedProject{
+			Project: p,
+			Score:   p.Frecency(now),
+			Match:   matches[0].Score,
+		})
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if ranked[i].Score != ranked[j].Score {
+			return ranked[i].Score > ranked[j].Score
+		}
+		if ranked[i].Match != ranked[j].Match {
+			return ranked[i].Match > ranked[j].Match
+		}
+		return ranked[i].Project.Name < ranked[j].Project.Name
+	})
+
+	return ranked
+}
@@ -0,0 +1,256 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// CurrentVersion is the config schema version written by this build.
+// Files without a version field are treated as version 0.
+const CurrentVersion = 4
+
+// migration upgrades a raw config document from one schema version to the next
+type migration struct {
+	From        int
+	Description string
+	Apply       func(doc map[string]any) error
+}
+
+// migrations lists every upgrade step in order; migrations[i].From must be i
+var migrations = []migration{
+	{
+		From:        0,
+		Description: "add schema version field",
+		Apply: func(doc map[string]any) error {
+			if doc["projects"] == nil {
+				doc["projects"] = []any{}
+			}
+			return nil
+		},
+	},
+	{
+		From:        1,
+		Description: "add project metadata (tags, description, aliases, timestamps)",
+		Apply: func(doc map[string]any) error {
+			projects, ok := doc["projects"].([]any)
+			if !ok {
+				return fmt.Errorf("projects must be a list")
+			}
+
+			// Existing projects get their creation time backfilled with the
+			// migration time, the earliest moment mpm can vouch for
+			now := time.Now().UTC().Format(time.RFC3339)
+			for _, raw := range projects {
+				project, ok := raw.(map[string]any)
+				if !ok {
+					return fmt.Errorf("project entries must be objects")
+				}
+				if _, ok := project["created_at"]; !ok {
+					project["created_at"] = now
+				}
+			}
+			return nil
+		},
+	},
+	{
+		From:        2,
+		Description: "remember the git identity of projects for relocate --search",
+		Apply: func(doc map[string]any) error {
+			projects, ok := doc["projects"].([]any)
+			if !ok {
+				return fmt.Errorf("projects must be a list")
+			}
+
+			for _, raw := range projects {
+				project, ok := raw.(map[string]any)
+				if !ok {
+					return fmt.Errorf("project entries must be objects")
+				}
+				if project["git_remote"] != nil || project["root_commit"] != nil {
+					continue
+				}
+				path, _ := project["path"].(string)
+				if path == "" {
+					continue
+				}
+
+				p := Project{Path: path}
+				p.rememberIdentity()
+				if p.GitRemote != "" {
+					project["git_remote"] = p.GitRemote
+				}
+				if p.RootCommit != "" {
+					project["root_commit"] = p.RootCommit
+				}
+			}
+			return nil
+		},
+	},
+	{
+		From:        3,
+		Description: "move the open history to the cache directory",
+		Apply: func(doc map[string]any) error {
+			projects, ok := doc["projects"].([]any)
+			if !ok {
+				return fmt.Errorf("projects must be a list")
+			}
+
+			// The store copies the history out of the original file
+			// before it is replaced
+			for _, raw := range projects {
+				project, ok := raw.(map[string]any)
+				if !ok {
+					return fmt.Errorf("project entries must be objects")
+				}
+				delete(project, "open_count")
+				delete(project, "last_opened")
+			}
+			return nil
+		},
+	},
+}
+
+// MigrationReport describes the upgrade applied (or planned) for a config file
+type MigrationReport struct {
+	FromVersion int
+	ToVersion   int
+	Steps       []string
+	Before      []byte
+	After       []byte
+	BackupPath  string
+}
+
+// Changed reports whether any migration step applies
+func (r MigrationReport) Changed() bool {
+	return len(r.Steps) > 0
+}
+
+// Migrate upgrades the config file to CurrentVersion. The original file is
+// kept next to it as a .bak before being replaced. With dryRun set nothing
+// is written and the report only describes what would change.
+func (s *Store) Migrate(dryRun bool) (MigrationReport, error) {
+	unlock, err := lockFile(s.lockPath(), true)
+	if err != nil {
+		return MigrationReport{}, err
+	}
+	defer unlock()
+
+	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return MigrationReport{FromVersion: CurrentVersion, ToVersion: CurrentVersion}, nil
+	}
+	if err != nil {
+		return MigrationReport{}, fmt.Errorf("reading config file: %w", err)
+	}
+
+	report, err := migrateData(data)
+	if err != nil {
+		return report, err
+	}
+
+	if !report.Changed() || dryRun {
+		return report, nil
+	}
+
+	if err := s.keepLegacyHistory(data); err != nil {
+		return report, err
+	}
+	report.BackupPath = s.backupPath(report.FromVersion)
+	if err := s.backup(data, report.FromVersion); err != nil {
+		return report, err
+	}
+	if err := writeFileAtomic(s.path, report.After, 0644); err != nil {
+		return report, err
+	}
+
+	return report, nil
+}
+
+// migrateData runs all pending migrations over raw config file contents
+func migrateData(data []byte) (MigrationReport, error) {
+	report := MigrationReport{Before: data, After: data}
+
+	var doc map[string]any
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&doc); err != nil {
+		return report, fmt.Errorf("parsing config file: %w", err)
+	}
+	if doc == nil {
+		doc = map[string]any{}
+	}
+
+	version, err := documentVersion(doc)
+	if err != nil {
+		return report, err
+	}
+	report.FromVersion = version
+	report.ToVersion = version
+
+	if version > CurrentVersion {
+		return report, fmt.Errorf("config file version %d is newer than this mpm supports (%d)", version, CurrentVersion)
+	}
+	if version == CurrentVersion {
+		return report, nil
+	}
+
+	for _, m := range migrations[version:] {
+		if err := m.Apply(doc); err != nil {
+			return report, fmt.Errorf("migrating config from version %d: %w", m.From, err)
+		}
+		doc["version"] = m.From + 1
+		report.Steps = append(report.Steps, fmt.Sprintf("v%d → v%d: %s", m.From, m.From+1, m.Description))
+	}
+	report.ToVersion = CurrentVersion
+
+	// Round-trip through Config so the file keeps its canonical layout
+	migrated, err := json.Marshal(doc)
+	if err != nil {
+		return report, fmt.Errorf("encoding migrated config: %w", err)
+	}
+	var config Config
+	if err := json.Unmarshal(migrated, &config); err != nil {
+		return report, fmt.Errorf("decoding migrated config: %w", err)
+	}
+	if report.After, err = encodeConfig(config); err != nil {
+		return report, err
+	}
+
+	return report, nil
+}
+
+// documentVersion extracts the schema version from a raw config document
+func documentVersion(doc map[string]any) (int, error) {
+	raw, ok := doc["version"]
+	if !ok || raw == nil {
+		return 0, nil
+	}
+
+	number, ok := raw.(json.Number)
+	if !ok {
+		return 0, fmt.Errorf("config version must be a number, got %v", raw)
+	}
+
+	version, err := number.Int64()
+	if err != nil || version < 0 {
+		return 0, fmt.Errorf("invalid config version %q", number)
+	}
+
+	return int(version), nil
+}
+
+// backupPath returns where the original file of the given version is kept
+func (s *Store) backupPath(version int) string {
+	return fmt.Sprintf("%s.v%d.bak", s.path, version)
+}
+
+// backup writes the original config contents before they are migrated
+func (s *Store) backup(data []byte, version int) error {
+	if err := writeFileAtomic(s.backupPath(version), data, 0644); err != nil {
+		return fmt.Errorf("backing up config file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// v0Config is a config file as written before the schema was versioned
+const v0Config = `{
+  "projects": [
+    {"name": "api", "path": "/code/api", "category": "work"},
+    {"name": "blog", "path": "/code/blog"}
+  ]
+}`
+
+func writeConfig(t *testing.T, data string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestMigrateV0(t *testing.T) {
+	path := writeConfig(t, v0Config)
+	start := time.Now().Truncate(time.Second)
+
+	report, err := NewStore(path).Migrate(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.FromVersion != 0 || report.ToVersion != CurrentVersion || len(report.Steps) != CurrentVersion {
+		t.Errorf("got report from v%d to v%d with steps %q", report.FromVersion, report.ToVersion, report.Steps)
+	}
+
+	// The original is kept byte for byte
+	wantBackup := path + ".v0.bak"
+	if report.BackupPath != wantBackup {
+		t.Errorf("backup at %s, want %s", report.BackupPath, wantBackup)
+	}
+	backup, err := os.ReadFile(wantBackup)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(backup) != v0Config {
+		t.Errorf("backup differs from the original:\n%s", backup)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc struct {
+		Version  int `json:"version"`
+		Projects []struct {
+			Name      string    `json:"name"`
+			Path      string    `json:"path"`
+			Category  string    `json:"category"`
+			CreatedAt time.Time `json:"created_at"`
+		} `json:"projects"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	if doc.Version != CurrentVersion {
+		t.Errorf("version %d, want %d", doc.Version, CurrentVersion)
+	}
+	if len(doc.Projects) != 2 {
+		t.Fatalf("got %d projects, want 2", len(doc.Projects))
+	}
+	api := doc.Projects[0]
+	if api.Name != "api" || api.Path != "/code/api" || api.Category != "work" {
+		t.Errorf("project changed: %+v", api)
+	}
+	for _, p := range doc.Projects {
+		if p.CreatedAt.Before(start) || p.CreatedAt.After(time.Now()) {
+			t.Errorf("%s: created_at %v not backfilled with the migration time", p.Name, p.CreatedAt)
+		}
+	}
+
+	// Migrating again has nothing left to do
+	report, err = NewStore(path).Migrate(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Changed() {
+		t.Errorf("second migration applied %q", report.Steps)
+	}
+}
+
+func TestMigrateDryRunWritesNothing(t *testing.T) {
+	path := writeConfig(t, v0Config)
+
+	report, err := NewStore(path).Migrate(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.Changed() {
+		t.Fatal("dry run found nothing to migrate")
+	}
+
+	data, _ := os.ReadFile(path)
+	if string(data) != v0Config {
+		t.Errorf("dry run rewrote the config:\n%s", data)
+	}
+	if _, err := os.Stat(path + ".v0.bak"); !os.IsNotExist(err) {
+		t.Errorf("dry run wrote a backup")
+	}
+}
+
+func TestUpdateBacksUpOlderSchema(t *testing.T) {
+	v1 := `{"version": 1, "projects": [{"name": "api", "path": "/code/api"}]}`
+	path := writeConfig(t, v1)
+
+	if err := addProjectNamed(NewStore(path), "web"); err != nil {
+		t.Fatal(err)
+	}
+
+	backup, err := os.ReadFile(path + ".v1.bak")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(backup) != v1 {
+		t.Errorf("backup differs from the original:\n%s", backup)
+	}
+	assertProjects(t, NewStore(path), []string{"api", "web"})
+}
+
+func TestMigrateRejectsNewerVersion(t *testing.T) {
+	newer := []byte(`{"version": 99, "projects": []}`)
+	path := writeConfig(t, string(newer))
+
+	if _, err := NewStore(path).Migrate(false); err == nil {
+		t.Fatal("a config from a newer mpm was accepted")
+	}
+	if _, err := NewStore(path).Load(); err == nil {
+		t.Fatal("a config from a newer mpm was loaded")
+	}
+
+	data, _ := os.ReadFile(path)
+	if !bytes.Equal(data, newer) {
+		t.Errorf("config was rewritten:\n%s", data)
+	}
+}
@@ -0,0 +1,476 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Store provides locked, atomic access to the config file.
+// Every read-modify-write goes through Update, which holds an advisory
+// lock on a sibling lock file so concurrent mpm processes cannot clobber
+// each other, and writes through a temp file + fsync + rename so a crash
+// never leaves a truncated config behind.
+type Store struct {
+	path string
+}
+
+// NewStore returns a store backed by the config file at path
+func NewStore(path string) *Store {
+	return &Store{path: path}
+}
+
+// Path returns the location of the config file
+func (s *Store) Path() string {
+	return s.path
+}
+
+// Load reads the configuration under a shared lock, with the open history
+// of the projects filled in
+func (s *Store) Load() (Config, error) {
+	unlock, err := lockFile(s.lockPath(), false)
+	if err != nil {
+		return Config{}, err
+	}
+	defer unlock()
+
+	config, _, err := s.read()
+	if err != nil {
+		return config, err
+	}
+
+	// Without its history a project only ranks lower
+	if history, err := s.loadHistory(); err == nil {
+		history.apply(config.Projects)
+	}
+	return config, nil
+}
+
+// Save replaces the configuration on disk
+func (s *Store) Save(config Config) error {
+	unlock, err := lockFile(s.lockPath(), true)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	return s.write(config)
+}
+
+// Update loads the configuration, applies fn and saves the result while
+// holding an exclusive lock. Nothing is written if fn returns an error.
+func (s *Store) Update(fn func(*Config) error) error {
+	unlock, err := lockFile(s.lockPath(), true)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	config, report, err := s.read()
+	if err != nil {
+		return err
+	}
+
+	if err := fn(&config); err != nil {
+		return err
+	}
+	if err := config.validate(); err != nil {
+		return err
+	}
+
+	// Keep the original around before overwriting an older schema
+	if report.Changed() {
+		if err := s.keepLegacyHistory(report.Before); err != nil {
+			return err
+		}
+		if err := s.backup(report.Before, report.FromVersion); err != nil {
+			return err
+		}
+	}
+
+	return s.write(config)
+}
+
+// AddProject adds a project or updates the one with the same name.
+// When updating, empty fields keep their current value so re-adding a
+// project without -c does not wipe its category; creation time is always
+// kept. It reports whether a project was updated.
+func (s *Store) AddProject(project Project) (bool, error) {
+	absPath, err := ExpandPath(project.Path)
+	if err != nil {
+		return false, err
+	}
+	project.Path = absPath
+	project.rememberIdentity()
+
+	now := time.Now()
+	project.LastModified = now
+
+	updated := false
+	err = s.Update(func(c *Config) error {
+		for i, p := range c.Projects {
+			if p.Name == project.Name {
+				if project.Category == "" {
+					project.Category = p.Category
+				}
+				if project.Description == "" {
+					project.Description = p.Description
+				}
+				if len(project.Tags) == 0 {
+					project.Tags = p.Tags
+				}
+				if len(project.Aliases) == 0 {
+					project.Aliases = p.Aliases
+				}
+				if project.Launcher == "" {
+					project.Launcher = p.Launcher
+				}
+				if len(project.TmuxWindows) == 0 {
+					project.TmuxWindows = p.TmuxWindows
+				}
+				if len(project.Tasks) == 0 {
+					project.Tasks = p.Tasks
+				}
+				if project.Parent == "" {
+					project.Parent = p.Parent
+				}
+				if !project.hasIdentity() && project.Path == p.Path {
+					project.GitRemote = p.GitRemote
+					project.RootCommit = p.RootCommit
+				}
+				project.CreatedAt = p.CreatedAt
+				c.Projects[i] = project
+				updated = true
+				return nil
+			}
+		}
+		project.CreatedAt = now
+		c.Projects = append(c.Projects, project)
+		return nil
+	})
+
+	return updated, err
+}
+
+// AddProjects registers several new projects in a single write. It fails
+// without changing anything if any of the names is already taken.
+func (s *Store) AddProjects(projects []Project) error {
+	now := time.Now()
+	for i := range projects {
+		absPath, err := ExpandPath(projects[i].Path)
+		if err != nil {
+			return err
+		}
+		projects[i].Path = absPath
+		projects[i].rememberIdentity()
+		projects[i].CreatedAt = now
+		projects[i].LastModified = now
+	}
+
+	return s.Update(func(c *Config) error {
+		c.Projects = append(c.Projects, projects...)
+		return nil
+	})
+}
+
+// UpdateProject applies fn to the project with the given name or alias and
+// bumps its last-modified time. Worktrees follow a renamed project, and the
+// git identity follows a moved one.
+func (s *Store) UpdateProject(name string, fn func(*Project) error) error {
+	var oldName, newName string
+	err := s.Update(func(c *Config) error {
+		i := c.indexOf(name)
+		if i < 0 {
+			return fmt.Errorf("%w: %s", ErrProjectNotFound, name)
+		}
+
+		oldPath := c.Projects[i].Path
+		oldName = c.Projects[i].Name
+		if err := fn(&c.Projects[i]); err != nil {
+			return err
+		}
+		c.Projects[i].LastModified = time.Now()
+		if c.Projects[i].Path != oldPath || !c.Projects[i].hasIdentity() {
+			c.Projects[i].rememberIdentity()
+		}
+
+		// Worktrees stay attached when fn renames the project
+		if newName = c.Projects[i].Name; newName != oldName {
+			for j := range c.Projects {
+				if c.Projects[j].Parent == oldName {
+					c.Projects[j].Parent = newName
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil || newName == oldName {
+		return err
+	}
+
+	// The history is a cache, losing it is not worth failing the rename
+	s.updateHistory(func(h History) { h.rename(oldName, newName) })
+	return nil
+}
+
+// RenameProject gives a project a new name, failing if it is already taken.
+// Its worktrees stay attached to it.
+func (s *Store) RenameProject(name, newName string) error {
+	newName = strings.TrimSpace(newName)
+	return s.UpdateProject(name, func(p *Project) error {
+		p.Name = newName
+		return nil
+	})
+}
+
+// MoveProject points a project at a new directory
+func (s *Store) MoveProject(name, newPath string) error {
+	absPath, err := ExpandPath(newPath)
+	if err != nil {
+		return err
+	}
+
+	return s.UpdateProject(name, func(p *Project) error {
+		p.Path = absPath
+		return nil
+	})
+}
+
+// MergeProjects folds the other projects into keep and removes them.
+// Tags and aliases are combined, the removed names become aliases of keep
+// and the open history is added up.
+func (s *Store) MergeProjects(keep string, others []string) error {
+	var target Project
+	remove := make(map[string]bool)
+	err := s.Update(func(c *Config) error {
+		k := c.indexOf(keep)
+		if k < 0 {
+			return fmt.Errorf("%w: %s", ErrProjectNotFound, keep)
+		}
+		target = c.Projects[k]
+
+		for _, name := range others {
+			i := c.indexOf(name)
+			if i < 0 {
+				return fmt.Errorf("%w: %s", ErrProjectNotFound, name)
+			}
+			p := c.Projects[i]
+			if p.Name == target.Name {
+				continue
+			}
+			remove[p.Name] = true
+
+			if target.Category == "" {
+				target.Category = p.Category
+			}
+			if target.Description == "" {
+				target.Description = p.Description
+			}
+			if target.Launcher == "" {
+				target.Launcher = p.Launcher
+			}
+			if len(target.TmuxWindows) == 0 {
+				target.TmuxWindows = p.TmuxWindows
+			}
+			if len(target.Tasks) == 0 {
+				target.Tasks = p.Tasks
+			}
+			target.Tags = NormalizeList(append(target.Tags, p.Tags...))
+			target.Aliases = NormalizeList(append(append(target.Aliases, p.Aliases...), p.Name))
+			if !p.CreatedAt.IsZero() && (target.CreatedAt.IsZero() || p.CreatedAt.Before(target.CreatedAt)) {
+				target.CreatedAt = p.CreatedAt
+			}
+		}
+		target.LastModified = time.Now()
+
+		var projects []Project
+		for _, p := range c.Projects {
+			// Worktrees of removed projects now belong to keep
+			if remove[p.Parent] {
+				p.Parent = target.Name
+			}
+			switch {
+			case p.Name == target.Name:
+				projects = append(projects, target)
+			case !remove[p.Name]:
+				projects = append(projects, p)
+			}
+		}
+		c.Projects = projects
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	s.updateHistory(func(h History) { h.merge(target.Name, slices.Collect(maps.Keys(remove))) })
+	return nil
+}
+
+// SetPaths points several projects, keyed by name, at new directories
+// in a single write, remembering the git identity found there
+func (s *Store) SetPaths(paths map[string]string) error {
+	now := time.Now()
+	return s.Update(func(c *Config) error {
+		for name, path := range paths {
+			i := c.indexOf(name)
+			if i < 0 {
+				return fmt.Errorf("%w: %s", ErrProjectNotFound, name)
+			}
+			c.Projects[i].Path = path
+			c.Projects[i].LastModified = now
+			c.Projects[i].rememberIdentity()
+		}
+		return nil
+	})
+}
+
+// SetCategoryLauncher sets the preferred launcher for projects in a
+// category, or removes it when launcher is empty
+func (s *Store) SetCategoryLauncher(category, launcher string) error {
+	return s.Update(func(c *Config) error {
+		if launcher == "" {
+			delete(c.CategoryLaunchers, category)
+			return nil
+		}
+		if c.CategoryLaunchers == nil {
+			c.CategoryLaunchers = make(map[string]string)
+		}
+		c.CategoryLaunchers[category] = launcher
+		return nil
+	})
+}
+
+// RemoveProject removes the project with exactly this name. Aliases are
+// not resolved, so a mistyped one cannot remove another project. Worktrees
+// of the project stay registered as projects of their own.
+func (s *Store) RemoveProject(name string) error {
+	err := s.Update(func(c *Config) error {
+		i := slices.IndexFunc(c.Projects, func(p Project) bool { return p.Name == name })
+		if i < 0 {
+			return fmt.Errorf("%w: %s", ErrProjectNotFound, name)
+		}
+		c.Projects = append(c.Projects[:i], c.Projects[i+1:]...)
+
+		for j := range c.Projects {
+			if c.Projects[j].Parent == name {
+				c.Projects[j].Parent = ""
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	s.updateHistory(func(h History) { delete(h, name) })
+	return nil
+}
+
+// GetProject returns the project registered under name or alias
+func (s *Store) GetProject(name string) (Project, error) {
+	config, err := s.Load()
+	if err != nil {
+		return Project{}, err
+	}
+
+	if p, ok := config.FindProject(name); ok {
+		return p, nil
+	}
+
+	return Project{}, fmt.Errorf("%w: %s", ErrProjectNotFound, name)
+}
+
+// ensureExists creates an empty config file if none is present yet
+func (s *Store) ensureExists() error {
+	unlock, err := lockFile(s.lockPath(), true)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if _, err := os.Stat(s.path); !os.IsNotExist(err) {
+		return err
+	}
+
+	return s.write(Config{Projects: []Project{}})
+}
+
+// lockPath returns the path of the advisory lock file
+func (s *Store) lockPath() string {
+	return s.path + ".lock"
+}
+
+// read loads the config file, treating a missing file as empty and
+// migrating older schemas in memory. Callers must hold the lock.
+func (s *Store) read() (Config, MigrationReport, error) {
+	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return Config{Version: CurrentVersion, Projects: []Project{}}, MigrationReport{}, nil
+	}
+	if err != nil {
+		return Config{}, MigrationReport{}, fmt.Errorf("reading config file: %w", err)
+	}
+
+	return decodeConfig(data)
+}
+
+// write atomically replaces the config file. Callers must hold the lock.
+func (s *Store) write(config Config) error {
+	data, err := encodeConfig(config)
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(s.path, data, 0644)
+}
+
+// writeFileAtomic writes data to a temp file in the same directory, syncs
+// it and renames it over path so readers only ever see a complete file
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	dir := filepath.Dir(path)
+
+	tmp, err := os.CreateTemp(dir, filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("creating temp config file: %w", err)
+	}
+	tmpName := tmp.Name()
+
+	// Clean up the temp file on any failure before the rename
+	success := false
+	defer func() {
+		if !success {
+			tmp.Close()
+			os.Remove(tmpName)
+		}
+	}()
+
+	if _, err := tmp.Write(data); err != nil {
+		return fmt.Errorf("writing temp config file: %w", err)
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		return fmt.Errorf("setting config file permissions: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		return fmt.Errorf("syncing temp config file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("closing temp config file: %w", err)
+	}
+	if err := os.Rename(tmpName, path); err != nil {
+		return fmt.Errorf("replacing config file: %w", err)
+	}
+	success = true
+
+	// Sync the directory so the rename itself is durable
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+
+	return nil
+}
@@ -0,0 +1,220 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+// TestMain keeps the open history the store writes out of the user's cache
+func TestMain(m *testing.M) {
+	cache, err := os.MkdirTemp("", "mpm-cache-")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	os.Setenv("XDG_CACHE_HOME", cache)
+
+	code := m.Run()
+	os.RemoveAll(cache)
+	os.Exit(code)
+}
+
+// addProjectNamed appends a project in its own Update, like one mpm command
+func addProjectNamed(s *Store, name string) error {
+	return s.Update(func(c *Config) error {
+		c.Projects = append(c.Projects, Project{Name: name, Path: "/code/" + name})
+		return nil
+	})
+}
+
+// assertProjects checks that the config file holds exactly the given names
+func assertProjects(t *testing.T, s *Store, names []string) {
+	t.Helper()
+
+	config, err := s.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(config.Projects) != len(names) {
+		t.Fatalf("got %d projects, want %d", len(config.Projects), len(names))
+	}
+	for _, name := range names {
+		if _, ok := config.FindProject(name); !ok {
+			t.Errorf("project %s was lost", name)
+		}
+	}
+}
+
+func TestUpdateConcurrentWritesAreNotLost(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+
+	const writers = 50
+	var names []string
+	var wg sync.WaitGroup
+	errs := make(chan error, writers)
+	for i := range writers {
+		name := fmt.Sprintf("p%02d", i)
+		names = append(names, name)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// A store of its own opens its own lock, like another process
+			errs <- addProjectNamed(NewStore(path), name)
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	assertProjects(t, NewStore(path), names)
+
+	// Temp files are renamed over the config, never left behind
+	leftovers, _ := filepath.Glob(path + ".tmp-*")
+	if len(leftovers) > 0 {
+		t.Errorf("temp files left behind: %v", leftovers)
+	}
+}
+
+// helperEnv makes the test binary act as a separate mpm process writing
+// projects to the config file it names
+const helperEnv = "MPM_TEST_STORE_HELPER"
+
+func TestUpdateConcurrentProcessesAreNotLost(t *testing.T) {
+	if path := os.Getenv(helperEnv); path != "" {
+		prefix := os.Getenv(helperEnv + "_PREFIX")
+		for i := range 10 {
+			if err := addProjectNamed(NewStore(path), prefix+strconv.Itoa(i)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return
+	}
+
+	path := filepath.Join(t.TempDir(), "config.json")
+
+	var names []string
+	var cmds []*exec.Cmd
+	for _, prefix := range []string{"a", "b", "c", "d"} {
+		for i := range 10 {
+			names = append(names, prefix+strconv.Itoa(i))
+		}
+
+		cmd := exec.Command(os.Args[0], "-test.run=^TestUpdateConcurrentProcessesAreNotLost$")
+		cmd.Env = append(os.Environ(), helperEnv+"="+path, helperEnv+"_PREFIX="+prefix)
+		if err := cmd.Start(); err != nil {
+			t.Fatal(err)
+		}
+		cmds = append(cmds, cmd)
+	}
+	for _, cmd := range cmds {
+		if err := cmd.Wait(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	assertProjects(t, NewStore(path), names)
+}
+
+func TestUpdateWritesNothingOnError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	s := NewStore(path)
+	if err := addProjectNamed(s, "api"); err != nil {
+		t.Fatal(err)
+	}
+	before, _ := os.ReadFile(path)
+
+	failure := errors.New("changed my mind")
+	err := s.Update(func(c *Config) error {
+		c.Projects = nil
+		return failure
+	})
+	if !errors.Is(err, failure) {
+		t.Fatalf("got error %v, want %v", err, failure)
+	}
+
+	// A config that fails validation is not written either
+	err = s.Update(func(c *Config) error {
+		c.Projects = append(c.Projects, Project{Name: "api", Path: "/elsewhere"})
+		return nil
+	})
+	if err == nil {
+		t.Fatal("duplicate project name was accepted")
+	}
+
+	after, _ := os.ReadFile(path)
+	if !bytes.Equal(before, after) {
+		t.Errorf("config file changed:\n%s\n---\n%s", before, after)
+	}
+}
+
+func TestRemoveProjectNeedsExactName(t *testing.T) {
+	s := NewStore(filepath.Join(t.TempDir(), "config.json"))
+	err := s.Update(func(c *Config) error {
+		c.Projects = []Project{
+			{Name: "api", Path: "/code/api", Aliases: []string{"a"}},
+			{Name: "api@fix", Path: "/code/api-fix", Parent: "api"},
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.RemoveProject("a"); !errors.Is(err, ErrProjectNotFound) {
+		t.Fatalf("removing by alias: got %v, want ErrProjectNotFound", err)
+	}
+	if err := s.RemoveProject("api"); err != nil {
+		t.Fatal(err)
+	}
+
+	config, err := s.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(config.Projects) != 1 || config.Projects[0].Parent != "" {
+		t.Errorf("worktree left pointing at the removed project: %+v", config.Projects)
+	}
+}
+
+func TestAddProjectKeepsFieldsWhenReadded(t *testing.T) {
+	s := NewStore(filepath.Join(t.TempDir(), "config.json"))
+	err := s.Update(func(c *Config) error {
+		c.Projects = []Project{
+			{Name: "api", Path: "/code/api"},
+			{Name: "api@fix", Path: "/code/api-fix", Category: "work", Tags: []string{"go"}, Parent: "api"},
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Re-adding with only a name and path, like `mpm add` without flags
+	updated, err := s.AddProject(Project{Name: "api@fix", Path: "/code/api-fix"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !updated {
+		t.Error("re-adding was not reported as an update")
+	}
+
+	p, err := s.GetProject("api@fix")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Parent != "api" || p.Category != "work" || len(p.Tags) != 1 {
+		t.Errorf("fields lost when re-adding: %+v", p)
+	}
+}
@@ -0,0 +1,138 @@
+package doctor
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"mpm/pkg/config"
+	"mpm/pkg/fs"
+)
+
+// IssueKind identifies the type of problem found in the project registry
+type IssueKind string
+
+const (
+	// MissingPath means the project's directory no longer exists
+	MissingPath IssueKind = "missing"
+	// DuplicatePath means several projects resolve to the same directory
+	DuplicatePath IssueKind = "duplicate-path"
+	// DuplicateRemote means several projects share the same git remote
+	DuplicateRemote IssueKind = "duplicate-remote"
+	// NameCollision means project names differ only in letter case
+	NameCollision IssueKind = "name-collision"
+)
+
+// Issue is a single problem affecting one or more projects
+type Issue struct {
+	Kind     IssueKind `json:"kind"`
+	Projects []string  `json:"projects"`
+	Detail   string    `json:"detail"`
+}
+
+// String describes the issue in one line
+func (i Issue) String() string {
+	switch i.Kind {
+	case MissingPath:
+		return fmt.Sprintf("'%s': path %s does not exist", i.Projects[0], i.Detail)
+	case DuplicatePath:
+		return fmt.Sprintf("%s all point to %s", quoteNames(i.Projects), i.Detail)
+	case DuplicateRemote:
+		return fmt.Sprintf("%s are clones of the same remote %s", quoteNames(i.Projects), i.Detail)
+	case NameCollision:
+		return fmt.Sprintf("%s differ only in letter case", quoteNames(i.Projects))
+	}
+	return string(i.Kind)
+}
+
+// Diagnose checks every registered project and returns the issues found,
+// grouped by kind
+func Diagnose(cfg config.Config) []Issue {
+	var issues []Issue
+
+	byPath := make(map[string][]string)
+	byRemote := make(map[string][]string)
+	byName := make(map[string][]string)
+	var pathOrder, remoteOrder, nameOrder []string
+
+	for _, p := range cfg.Projects {
+		lowerName := strings.ToLower(p.Name)
+		if _, ok := byName[lowerName]; !ok {
+			nameOrder = append(nameOrder, lowerName)
+		}
+		byName[lowerName] = append(byName[lowerName], p.Name)
+
+		if !fs.PathExists(p.Path) {
+			issues = append(issues, Issue{Kind: MissingPath, Projects: []string{p.Name}, Detail: p.Path})
+			continue
+		}
+
+		resolved := fs.CanonicalPath(p.Path)
+		if _, ok := byPath[resolved]; !ok {
+			pathOrder = append(pathOrder, resolved)
+		}
+		byPath[resolved] = append(byPath[resolved], p.Name)
+
+		// Only consider the repository remote once per directory
+		if len(byPath[resolved]) > 1 {
+			continue
+		}
+		// Worktrees, submodules and subdirectories legitimately share a
+		// remote with other projects
+		gitInfo := fs.CheckGitRepo(p.Path)
+		if !gitInfo.IsRepoRoot() {
+			continue
+		}
+		if remote := fs.NormalizeRemote(fs.PrimaryRemoteURL(gitInfo)); remote != "" {
+			if _, ok := byRemote[remote]; !ok {
+				remoteOrder = append(remoteOrder, remote)
+			}
+			byRemote[remote] = append(byRemote[remote], p.Name)
+		}
+	}
+
+	for _, path := range pathOrder {
+		if names := byPath[path]; len(names) > 1 {
+			issues = append(issues, Issue{Kind: DuplicatePath, Projects: names, Detail: path})
+		}
+	}
+	for _, remote := range remoteOrder {
+		if names := byRemote[remote]; len(names) > 1 {
+			issues = append(issues, Issue{Kind: DuplicateRemote, Projects: names, Detail: remote})
+		}
+	}
+	for _, name := range nameOrder {
+		if names := byName[name]; len(names) > 1 {
+			issues = append(issues, Issue{Kind: NameCollision, Projects: names})
+		}
+	}
+
+	sort.SliceStable(issues, func(i, j int) bool {
+		return kindOrder(issues[i].Kind) < kindOrder(issues[j].Kind)
+	})
+
+	return issues
+}
+
+// kindOrder defines the order in which issue kinds are reported
+func kindOrder(kind IssueKind) int {
+	switch kind {
+	case MissingPath:
+		return 0
+	case DuplicatePath:
+		return 1
+	case DuplicateRemote:
+		return 2
+	default:
+		return 3
+	}
+}
+
+// quoteNames formats project names for messages
+func quoteNames(names []string) string {
+	quoted := make([]string, len(names))
+	for i, n := range names {
+		quoted[i] = "'" + n + "'"
+	}
+	return strings.Join(quoted, ", ")
+}
@@ -0,0 +1,56 @@
+package format
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// YAML encodes v as YAML. The value goes through encoding/json first, so
+// json struct tags, omitempty and custom marshalers all apply and fields
+// keep their declaration order.
+func YAML(v any) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	// JSON is YAML, so the parsed document keeps the key order
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("encoding YAML: %w", err)
+	}
+	if err := restyle(&doc); err != nil {
+		return nil, fmt.Errorf("encoding YAML: %w", err)
+	}
+
+	var b bytes.Buffer
+	encoder := yaml.NewEncoder(&b)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&doc); err != nil {
+		return nil, fmt.Errorf("encoding YAML: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, fmt.Errorf("encoding YAML: %w", err)
+	}
+	return b.Bytes(), nil
+}
+
+// restyle drops the flow style and quotes the JSON input was parsed with.
+// Strings are encoded again on their own, which quotes the ones YAML 1.1
+// readers would take for something else, like yes, on or 12:30.
+func restyle(n *yaml.Node) error {
+	if n.Kind == yaml.ScalarNode && n.Tag == "!!str" {
+		return n.Encode(n.Value)
+	}
+
+	n.Style = 0
+	for _, child := range n.Content {
+		if err := restyle(child); err != nil {
+			return err
+		}
+	}
+	return nil
+}
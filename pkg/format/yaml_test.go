@@ -0,0 +1,88 @@
+package format
+
+import (
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestYAMLQuotesAmbiguousStrings(t *testing.T) {
+	tests := []string{
+		"2024-01-01",
+		"2024-01-01T10:00:00Z",
+		"12:30",
+		".inf",
+		"-.Inf",
+		".nan",
+		"0x1F",
+		"0o17",
+		"1e3",
+		"0123",
+		"yes", "No", "on", "OFF", "y", "n",
+		"true", "False",
+		"null", "~", "",
+		"- item",
+		"key: value",
+		"#comment",
+		"&anchor",
+		"*alias",
+		"!tag",
+		"@at",
+		"`tick",
+		"%percent",
+		" leading",
+		"trailing ",
+		"line\nbreak",
+		"tab\there",
+		`quote "double" 'single'`,
+		"/tmp/we ird $(touch x)'q",
+		"plain text",
+	}
+
+	for _, s := range tests {
+		out, err := YAML(map[string]string{"v": s})
+		if err != nil {
+			t.Fatalf("YAML(%q): %v", s, err)
+		}
+
+		var back map[string]any
+		if err := yaml.Unmarshal(out, &back); err != nil {
+			t.Fatalf("reading back %q: %v\n%s", s, err, out)
+		}
+		if got, ok := back["v"].(string); !ok || got != s {
+			t.Errorf("%q came back as %#v from:\n%s", s, back["v"], out)
+		}
+
+		// YAML 1.1 readers take these for booleans or numbers
+		if s == "yes" || s == "on" || s == "12:30" {
+			if string(out) == "v: "+s+"\n" {
+				t.Errorf("%q is not quoted:\n%s", s, out)
+			}
+		}
+	}
+}
+
+func TestYAMLKeepsFieldOrder(t *testing.T) {
+	type project struct {
+		Name string   `json:"name"`
+		Path string   `json:"path"`
+		Tags []string `json:"tags,omitempty"`
+		Note string   `json:"note,omitempty"`
+	}
+
+	out, err := YAML([]project{{Name: "api", Path: "/code/api", Tags: []string{"go"}}, {Name: "web", Path: "/code/web"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `- name: api
+  path: /code/api
+  tags:
+    - go
+- name: web
+  path: /code/web
+`
+	if string(out) != want {
+		t.Errorf("got:\n%s\nwant:\n%s", out, want)
+	}
+}
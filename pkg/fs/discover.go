@@ -0,0 +1,100 @@
+package fs
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// projectMarkers are files or directories whose presence marks a project root
+var projectMarkers = []string{
+	".git", "go.mod", "package.json", "Cargo.toml", "pyproject.toml",
+	"setup.py", "requirements.txt", "pom.xml", "build.gradle", "build.gradle.kts",
+	"composer.json", "Gemfile", "mix.exs", "deno.json", "CMakeLists.txt",
+	"Package.swift", "pubspec.yaml",
+}
+
+// DiscoveredProject is a project root found while scanning a directory tree
+type DiscoveredProject struct {
+	Path    string   `json:"path"`
+	Markers []string `json:"markers"`
+	Remote  string   `json:"remote,omitempty"`
+}
+
+// DiscoverProjects walks root up to maxDepth levels deep and returns every
+// directory that looks like a project root. Excluded directories (see
+// ShouldExclude) are skipped and nothing below a project root is searched,
+// so nested packages of a monorepo are not reported separately.
+func DiscoverProjects(root string, maxDepth int) ([]DiscoveredProject, error) {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(root); err != nil {
+		return nil, err
+	}
+
+	var found []DiscoveredProject
+	discoverDir(root, maxDepth, 0, &found)
+
+	sort.Slice(found, func(i, j int) bool {
+		return found[i].Path < found[j].Path
+	})
+
+	return found, nil
+}
+
+// discoverDir checks a single directory and recurses into its children
+func discoverDir(dir string, maxDepth, depth int, found *[]DiscoveredProject) {
+	if markers := detectMarkers(dir); len(markers) > 0 {
+		project := DiscoveredProject{Path: dir, Markers: markers}
+		if gitInfo := CheckGitRepo(dir); gitInfo.HasGit {
+			project.Remote = PrimaryRemoteURL(gitInfo)
+		}
+		*found = append(*found, project)
+		return
+	}
+
+	if maxDepth > 0 && depth >= maxDepth {
+		return
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+
+	for _, entry := range entries {
+		path := filepath.Join(dir, entry.Name())
+		if !entry.IsDir() || ShouldExclude(path) {
+			continue
+		}
+		discoverDir(path, maxDepth, depth+1, found)
+	}
+}
+
+// detectMarkers returns the project markers present in dir
+func detectMarkers(dir string) []string {
+	var markers []string
+	for _, marker := range projectMarkers {
+		if _, err := os.Stat(filepath.Join(dir, marker)); err == nil {
+			markers = append(markers, marker)
+		}
+	}
+	return markers
+}
+
+// CanonicalPath resolves symlinks so the same directory always compares
+// equal, falling back to the cleaned path if it cannot be resolved
+func CanonicalPath(path string) string {
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		return resolved
+	}
+	return filepath.Clean(path)
+}
+
+// PathExists reports whether path is an existing directory
+func PathExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
@@ -1,55 +1,220 @@
 package fs
 
 import (
+	"context"
 	"fmt"
-	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/lipgloss"
 )
 
 // GitInfo represents Git repository information
 type GitInfo struct {
-	HasGit  bool
-	Remotes []GitRemote
+	HasGit       bool          `json:"has_git"`
+	Root         string        `json:"root,omitempty"`         // Top-level directory of the working tree
+	Subdir       string        `json:"subdir,omitempty"`       // Where the project lies below Root, empty at the top level
+	CommonDir    string        `json:"common_dir,omitempty"`   // Git directory shared by all worktrees of the repository
+	Worktree     bool          `json:"worktree"`               // A linked worktree rather than the main one
+	Submodule    bool          `json:"submodule"`              // A submodule of another repository
+	Superproject string        `json:"superproject,omitempty"` // Working tree of the repository containing the submodule
+	Worktrees    []GitWorktree `json:"worktrees,omitempty"`    // Every working tree of the repository, the main one first
+	Branch       string        `json:"branch,omitempty"`       // Empty on a detached HEAD
+	Detached     bool          `json:"detached"`               // HEAD points at a commit, not a branch
+	Head         string        `json:"head,omitempty"`         // Abbreviated commit of HEAD, empty before the first commit
+	Upstream     string        `json:"upstream,omitempty"`     // Tracking branch, e.g. origin/main
+	Ahead        int           `json:"ahead"`                  // Commits not pushed to the upstream
+	Behind       int           `json:"behind"`                 // Upstream commits not pulled yet
+	Staged       int           `json:"staged"`                 // Files with changes in the index
+	Unstaged     int           `json:"unstaged"`               // Tracked files with changes not in the index
+	Untracked    int           `json:"untracked"`
+	Conflicts    int           `json:"conflicts"` // Files with unresolved merge conflicts
+	Stashes      int           `json:"stashes"`
+	LastCommit   time.Time     `json:"last_commit,omitzero"` // Commit time of HEAD
+	Remotes      []GitRemote   `json:"remotes"`
+}
+
+// GitWorktree is one working tree of a repository
+type GitWorktree struct {
+	Path     string `json:"path"`
+	Head     string `json:"head,omitempty"`   // Abbreviated commit checked out
+	Branch   string `json:"branch,omitempty"` // Empty when detached
+	Detached bool   `json:"detached"`
+	Bare     bool   `json:"bare"`     // The bare repository the worktrees belong to
+	Prunable bool   `json:"prunable"` // Its directory is gone and `git worktree prune` would drop it
+}
+
+// IsRepoRoot reports whether the project is the top level of its
+// repository's main working tree, rather than a subdirectory, a linked
+// worktree or a submodule that shares its identity with other projects
+func (g GitInfo) IsRepoRoot() bool {
+	return g.HasGit && g.Subdir == "" && !g.Worktree && !g.Submodule
+}
+
+// Dirty reports whether the working tree has uncommitted changes
+func (g GitInfo) Dirty() bool {
+	return g.Staged+g.Unstaged+g.Untracked+g.Conflicts > 0
 }
 
 // GitRemote represents a Git remote
 type GitRemote struct {
-	Name string
-	URL  string
+	Name string `json:"name"`
+	URL  string `json:"url"`
 }
 
-// CheckGitStatus checks if a directory is a Git repository and returns Git information
+// CheckGitStatus checks if a directory is a Git repository and returns its
+// remotes along with the branch, upstream and working tree status
 func CheckGitStatus(projectPath string) GitInfo {
+	gitInfo, _ := CheckGitStatusContext(context.Background(), projectPath)
+	return gitInfo
+}
+
+// CheckGitStatusContext is CheckGitStatus with a deadline. Git commands
+// still running when ctx is done are killed, and the context's error is
+// returned along with whatever was collected.
+func CheckGitStatusContext(ctx context.Context, projectPath string) (GitInfo, error) {
+	gitInfo := CheckGitRepoContext(ctx, projectPath)
+	if !gitInfo.HasGit {
+		return gitInfo, ctx.Err()
+	}
+
+	// Don't take the index lock, another git command may be running
+	cmd := exec.CommandContext(ctx, "git", "--no-optional-locks", "status", "--porcelain=v2", "--branch")
+	cmd.Dir = projectPath
+	if output, err := cmd.Output(); err == nil {
+		parseStatus(&gitInfo, string(output))
+	}
+
+	cmd = exec.CommandContext(ctx, "git", "log", "-1", "--format=%ct")
+	cmd.Dir = projectPath
+	if output, err := cmd.Output(); err == nil {
+		if seconds, err := strconv.ParseInt(strings.TrimSpace(string(output)), 10, 64); err == nil {
+			gitInfo.LastCommit = time.Unix(seconds, 0)
+		}
+	}
+
+	gitInfo.Worktrees = listWorktrees(ctx, projectPath)
+
+	cmd = exec.CommandContext(ctx, "git", "stash", "list")
+	cmd.Dir = projectPath
+	if output, err := cmd.Output(); err == nil {
+		for _, line := range strings.Split(string(output), "\n") {
+			if line != "" {
+				gitInfo.Stashes++
+			}
+		}
+	}
+
+	return gitInfo, ctx.Err()
+}
+
+// parseStatus reads the output of `git status --porcelain=v2 --branch`
+func parseStatus(gitInfo *GitInfo, output string) {
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+
+		switch fields[0] {
+		case "#":
+			// Branch headers: "# branch.<name> <value>"
+			if len(fields) < 3 {
+				continue
+			}
+			switch fields[1] {
+			case "branch.oid":
+				if fields[2] != "(initial)" && len(fields[2]) >= 7 {
+					gitInfo.Head = fields[2][:7]
+				}
+			case "branch.head":
+				if fields[2] == "(detached)" {
+					gitInfo.Detached = true
+				} else {
+					gitInfo.Branch = fields[2]
+				}
+			case "branch.upstream":
+				gitInfo.Upstream = fields[2]
+			case "branch.ab":
+				// "# branch.ab +<ahead> -<behind>"
+				if len(fields) >= 4 {
+					gitInfo.Ahead, _ = strconv.Atoi(strings.TrimPrefix(fields[2], "+"))
+					gitInfo.Behind, _ = strconv.Atoi(strings.TrimPrefix(fields[3], "-"))
+				}
+			}
+		case "1", "2":
+			// Changed or renamed entries: XY holds the index and work tree
+			// states, '.' meaning unmodified
+			xy := fields[1]
+			if len(xy) == 2 {
+				if xy[0] != '.' {
+					gitInfo.Staged++
+				}
+				if xy[1] != '.' {
+					gitInfo.Unstaged++
+				}
+			}
+		case "u":
+			gitInfo.Conflicts++
+		case "?":
+			gitInfo.Untracked++
+		}
+	}
+}
+
+// CheckGitRepo checks if a directory is a Git repository and returns its
+// remotes, without the slower working tree status
+func CheckGitRepo(projectPath string) GitInfo {
+	return CheckGitRepoContext(context.Background(), projectPath)
+}
+
+// CheckGitRepoContext is CheckGitRepo with a deadline, killing git commands
+// still running when ctx is done
+func CheckGitRepoContext(ctx context.Context, projectPath string) GitInfo {
 	gitInfo := GitInfo{
 		HasGit:  false,
 		Remotes: []GitRemote{},
 	}
 
-	// Check if .git directory exists
-	gitDir := filepath.Join(projectPath, ".git")
-	if _, err := os.Stat(gitDir); os.IsNotExist(err) {
+	// Ask git rather than looking for a .git directory: in worktrees and
+	// submodules .git is a file, and the project may be a subdirectory of
+	// the repository. The superproject line is only printed for submodules.
+	cmd := exec.CommandContext(ctx, "git", "rev-parse", "--show-toplevel", "--git-dir", "--git-common-dir", "--show-prefix", "--show-superproject-working-tree")
+	cmd.Dir = projectPath
+	output, err := cmd.Output()
+	if err != nil {
+		return gitInfo
+	}
+	lines := strings.Split(strings.TrimSuffix(string(output), "\n"), "\n")
+	if len(lines) < 4 {
 		return gitInfo
 	}
 
-	// Set HasGit to true since .git directory exists
 	gitInfo.HasGit = true
+	gitInfo.Root = lines[0]
+	gitInfo.Subdir = strings.TrimSuffix(lines[3], "/")
+	gitDir := absGitPath(projectPath, lines[1])
+	gitInfo.CommonDir = absGitPath(projectPath, lines[2])
+	gitInfo.Worktree = gitDir != gitInfo.CommonDir
+	if len(lines) > 4 && lines[4] != "" {
+		gitInfo.Submodule = true
+		gitInfo.Superproject = lines[4]
+	}
 
 	// Get remotes
-	cmd := exec.Command("git", "remote", "-v")
+	cmd = exec.CommandContext(ctx, "git", "remote", "-v")
 	cmd.Dir = projectPath
-	output, err := cmd.Output()
+	output, err = cmd.Output()
 	if err != nil {
 		return gitInfo
 	}
 
 	// Parse remotes
 	remoteMap := make(map[string]string)
-	lines := strings.Split(string(output), "\n")
-	for _, line := range lines {
+	for _, line := range strings.Split(string(output), "\n") {
 		if line == "" {
 			continue
 		}
@@ -85,6 +250,7 @@ func RenderGitInfo(gitInfo GitInfo) string {
 	gitPresentColor := "#A8CC8C"
 	gitAbsentColor := "#FF5555"
 	gitRemoteColor := "#7D56F4"
+	gitDirtyColor := "#E5C07B"
 
 	// Git status indicator with styled output
 	if gitInfo.HasGit {
@@ -96,6 +262,42 @@ func RenderGitInfo(gitInfo GitInfo) string {
 		return b.String()
 	}
 
+	switch {
+	case gitInfo.Submodule:
+		b.WriteString("  Submodule of: " + gitInfo.Superproject + "\n")
+	case gitInfo.Worktree:
+		b.WriteString("  Worktree of: " + mainWorktree(gitInfo) + "\n")
+	}
+	if gitInfo.Subdir != "" {
+		b.WriteString(fmt.Sprintf("  Repository: %s (project in %s/)\n", gitInfo.Root, gitInfo.Subdir))
+	}
+	b.WriteString("  Branch: " + renderBranch(gitInfo) + "\n")
+
+	cleanStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(gitPresentColor))
+	dirtyStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(gitDirtyColor))
+	if gitInfo.Dirty() {
+		var changes []string
+		for _, c := range []struct {
+			count int
+			label string
+		}{
+			{gitInfo.Conflicts, "conflicted"},
+			{gitInfo.Staged, "staged"},
+			{gitInfo.Unstaged, "unstaged"},
+			{gitInfo.Untracked, "untracked"},
+		} {
+			if c.count > 0 {
+				changes = append(changes, fmt.Sprintf("%d %s", c.count, c.label))
+			}
+		}
+		b.WriteString("  Changes: " + dirtyStyle.Render(strings.Join(changes, ", ")) + "\n")
+	} else {
+		b.WriteString("  Changes: " + cleanStyle.Render("clean") + "\n")
+	}
+	if gitInfo.Stashes > 0 {
+		b.WriteString(fmt.Sprintf("  Stashes: %d\n", gitInfo.Stashes))
+	}
+
 	// Remotes with styled output
 	if len(gitInfo.Remotes) > 0 {
 		b.WriteString("  Remotes:\n")
@@ -107,3 +309,210 @@ func RenderGitInfo(gitInfo GitInfo) string {
 
 	return b.String()
 }
+
+// absGitPath resolves a path printed by git rev-parse, which may be
+// relative to the directory git ran in
+func absGitPath(dir, path string) string {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+	return CanonicalPath(path)
+}
+
+// ListWorktrees returns the working trees of the repository containing
+// path, the main one first
+func ListWorktrees(path string) []GitWorktree {
+	return listWorktrees(context.Background(), path)
+}
+
+// listWorktrees implements ListWorktrees, stopping when ctx is done
+func listWorktrees(ctx context.Context, path string) []GitWorktree {
+	cmd := exec.CommandContext(ctx, "git", "worktree", "list", "--porcelain")
+	cmd.Dir = path
+	output, err := cmd.Output()
+	if err != nil {
+		return nil
+	}
+
+	// Worktrees are blocks of "<attribute> <value>" lines separated by a
+	// blank line, each starting with "worktree <path>"
+	var worktrees []GitWorktree
+	for _, line := range strings.Split(string(output), "\n") {
+		attr, value, _ := strings.Cut(line, " ")
+		if attr == "worktree" {
+			worktrees = append(worktrees, GitWorktree{Path: value})
+			continue
+		}
+		if len(worktrees) == 0 {
+			continue
+		}
+
+		w := &worktrees[len(worktrees)-1]
+		switch attr {
+		case "HEAD":
+			if len(value) >= 7 {
+				w.Head = value[:7]
+			}
+		case "branch":
+			w.Branch = strings.TrimPrefix(value, "refs/heads/")
+		case "detached":
+			w.Detached = true
+		case "bare":
+			w.Bare = true
+		case "prunable":
+			w.Prunable = true
+		}
+	}
+	return worktrees
+}
+
+// RenderWorktrees lists the working trees of the repository when it has
+// more than one, marking the project's own and naming the ones registered
+// as projects. names maps canonical paths to project names.
+func RenderWorktrees(gitInfo GitInfo, names map[string]string) string {
+	if len(gitInfo.Worktrees) < 2 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString("  Worktrees:\n")
+	current := CanonicalPath(gitInfo.Root)
+	for _, w := range gitInfo.Worktrees {
+		marker := "•"
+		if CanonicalPath(w.Path) == current {
+			marker = "▸"
+		}
+
+		ref := w.Branch
+		switch {
+		case w.Bare:
+			ref = "(bare)"
+		case w.Detached:
+			ref = "detached at " + w.Head
+		}
+		line := fmt.Sprintf("    %s %s  %s", marker, w.Path, ref)
+		if name, ok := names[CanonicalPath(w.Path)]; ok {
+			line += lipgloss.NewStyle().Foreground(lipgloss.Color("#7D56F4")).Render("  [" + name + "]")
+		}
+		if w.Prunable {
+			line += "  (missing, prunable)"
+		}
+		b.WriteString(line + "\n")
+	}
+	return b.String()
+}
+
+// mainWorktree returns the main working tree of a repository, or its git
+// directory when the main working tree is unknown or bare
+func mainWorktree(gitInfo GitInfo) string {
+	if len(gitInfo.Worktrees) > 0 && !gitInfo.Worktrees[0].Bare {
+		return gitInfo.Worktrees[0].Path
+	}
+	if filepath.Base(gitInfo.CommonDir) == ".git" {
+		return filepath.Dir(gitInfo.CommonDir)
+	}
+	return gitInfo.CommonDir
+}
+
+// renderBranch describes HEAD: the branch and how it compares to its
+// upstream, or the commit when detached
+func renderBranch(gitInfo GitInfo) string {
+	if gitInfo.Detached {
+		return "HEAD detached at " + gitInfo.Head
+	}
+	if gitInfo.Head == "" {
+		return gitInfo.Branch + " (no commits yet)"
+	}
+	if gitInfo.Upstream == "" {
+		return gitInfo.Branch + " (no upstream)"
+	}
+
+	branch := gitInfo.Branch + " → " + gitInfo.Upstream
+	switch {
+	case gitInfo.Ahead > 0 && gitInfo.Behind > 0:
+		branch += fmt.Sprintf(" (↑%d ↓%d)", gitInfo.Ahead, gitInfo.Behind)
+	case gitInfo.Ahead > 0:
+		branch += fmt.Sprintf(" (↑%d)", gitInfo.Ahead)
+	case gitInfo.Behind > 0:
+		branch += fmt.Sprintf(" (↓%d)", gitInfo.Behind)
+	default:
+		branch += " (up to date)"
+	}
+	return branch
+}
+
+// PrimaryRemoteURL returns the URL of the origin remote, or of the first
+// remote if there is no origin
+func PrimaryRemoteURL(gitInfo GitInfo) string {
+	var first string
+	for _, remote := range gitInfo.Remotes {
+		if remote.Name == "origin" {
+			return remote.URL
+		}
+		if first == "" {
+			first = remote.URL
+		}
+	}
+	return first
+}
+
+// RemoteOwner extracts the owner (user or organization) from a remote URL
+// such as https://github.com/owner/repo.git or git@github.com:owner/repo.git
+func RemoteOwner(url string) string {
+	url = strings.TrimSuffix(strings.TrimSpace(url), ".git")
+	url = strings.TrimSuffix(url, "/")
+
+	// Normalize scp-like syntax (git@host:owner/repo) to a path
+	if i := strings.Index(url, "://"); i >= 0 {
+		url = url[i+3:]
+	} else if i := strings.Index(url, ":"); i >= 0 {
+		url = url[:i] + "/" + url[i+1:]
+	}
+
+	parts := strings.Split(url, "/")
+	if len(parts) < 3 {
+		return ""
+	}
+	return parts[len(parts)-2]
+}
+
+// NormalizeRemote reduces a git remote URL to host/owner/repo so that the
+// HTTPS and SSH forms of the same repository compare equal
+func NormalizeRemote(url string) string {
+	url = strings.ToLower(strings.TrimSpace(url))
+	if url == "" {
+		return ""
+	}
+
+	url = strings.TrimSuffix(strings.TrimSuffix(url, "/"), ".git")
+	if i := strings.Index(url, "://"); i >= 0 {
+		url = url[i+3:]
+	} else if i := strings.Index(url, ":"); i >= 0 {
+		// scp-like syntax: git@host:owner/repo
+		url = url[:i] + "/" + url[i+1:]
+	}
+	if i := strings.Index(url, "@"); i >= 0 && i < strings.Index(url+"/", "/") {
+		url = url[i+1:]
+	}
+
+	return url
+}
+
+// RootCommit returns the hash of the repository's first commit, which
+// identifies a clone regardless of where it lives or how its remote is named.
+// It returns an empty string outside a repository or without commits.
+func RootCommit(projectPath string) string {
+	cmd := exec.Command("git", "rev-list", "--max-parents=0", "HEAD")
+	cmd.Dir = projectPath
+	output, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+
+	// Merged histories can have several roots; the last one is the oldest
+	roots := strings.Fields(string(output))
+	if len(roots) == 0 {
+		return ""
+	}
+	return roots[len(roots)-1]
+}
@@ -20,9 +20,9 @@ type FileEntry struct {
 
 // FileTypeCount tracks the count of file types
 type FileTypeCount struct {
-	Extension string
-	Count     int
-	Color     lipgloss.Color
+	Extension string         `json:"extension"`
+	Count     int            `json:"count"`
+	Color     lipgloss.Color `json:"-"`
 }
 
 // ShouldExclude determines if a file/directory should be excluded from scanning
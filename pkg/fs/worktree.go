@@ -0,0 +1,87 @@
+package fs
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// BranchExists reports whether the repository at repoPath has a local
+// branch, or a remote-tracking branch git would check out for it
+func BranchExists(repoPath, branch string) bool {
+	cmd := exec.Command("git", "for-each-ref", "--count=1", "--format=%(refname)",
+		"refs/heads/"+branch, "refs/remotes/*/"+branch)
+	cmd.Dir = repoPath
+	output, err := cmd.Output()
+	return err == nil && strings.TrimSpace(string(output)) != ""
+}
+
+// ValidBranch reports whether name can be used as a branch name
+func ValidBranch(name string) bool {
+	return exec.Command("git", "check-ref-format", "--branch", name).Run() == nil
+}
+
+// AddWorktree checks out branch into a new worktree at path, creating the
+// branch from base (or HEAD) when it does not exist yet. git's progress
+// messages go to stderr.
+func AddWorktree(repoPath, path, branch, base string) error {
+	args := []string{"worktree", "add", path, branch}
+	if !BranchExists(repoPath, branch) {
+		args = []string{"worktree", "add", "-b", branch, path}
+		if base != "" {
+			args = append(args, base)
+		}
+	} else if base != "" {
+		return fmt.Errorf("branch '%s' already exists, it cannot start at '%s'", branch, base)
+	}
+
+	return runGit(repoPath, args...)
+}
+
+// RemoveWorktree deletes a linked worktree and its directory. git refuses
+// to remove a worktree with uncommitted changes unless force is set.
+func RemoveWorktree(repoPath, path string, force bool) error {
+	args := []string{"worktree", "remove", path}
+	if force {
+		args = []string{"worktree", "remove", "--force", path}
+	}
+	return runGit(repoPath, args...)
+}
+
+// PruneWorktrees drops the records of worktrees whose directory is gone
+func PruneWorktrees(repoPath string) error {
+	return runGit(repoPath, "worktree", "prune")
+}
+
+// GoneBranches returns the local branches whose upstream branch was
+// deleted, typically after their pull request was merged
+func GoneBranches(repoPath string) map[string]bool {
+	gone := make(map[string]bool)
+
+	cmd := exec.Command("git", "for-each-ref", "--format=%(refname:short)%00%(upstream:track)", "refs/heads")
+	cmd.Dir = repoPath
+	output, err := cmd.Output()
+	if err != nil {
+		return gone
+	}
+
+	for _, line := range strings.Split(string(output), "\n") {
+		if branch, track, ok := strings.Cut(line, "\x00"); ok && track == "[gone]" {
+			gone[branch] = true
+		}
+	}
+	return gone
+}
+
+// runGit runs a git command in dir, passing its messages on to stderr
+func runGit(dir string, args ...string) error {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("git %s %s failed: %w", args[0], args[1], err)
+	}
+	return nil
+}
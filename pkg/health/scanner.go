@@ -1,6 +1,7 @@
 package health
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
@@ -15,67 +16,89 @@ import (
 
 // HealthStatus represents the overall health status of a project
 type HealthStatus struct {
-	DependencyStatus DependencyStatus
-	GitMetrics       GitMetrics
-	CIStatus         CIStatus
-	LastScanTime     time.Time
+	DependencyStatus DependencyStatus `json:"dependencies"`
+	GitMetrics       GitMetrics       `json:"git"`
+	CIStatus         CIStatus         `json:"ci"`
+	LastScanTime     time.Time        `json:"last_scan_time"`
 }
 
 // DependencyStatus represents dependency health information
 type DependencyStatus struct {
-	HasLockFile     bool
-	PackageManager  string
-	TotalDeps       int
-	OutdatedDeps    int
-	Vulnerabilities int
+	HasLockFile     bool   `json:"has_lock_file"`
+	PackageManager  string `json:"package_manager"`
+	TotalDeps       int    `json:"total_deps"`
+	OutdatedDeps    int    `json:"outdated_deps"`
+	Vulnerabilities int    `json:"vulnerabilities"`
 }
 
-// GitMetrics represents Git-related metrics
+// GitMetrics represents Git-related metrics. The GitHub counts are
+// placeholders until they are fetched from the API, so they are left out
+// of JSON output.
 type GitMetrics struct {
-	LastCommitDate time.Time
-	OpenPRs        int
-	OpenIssues     int
-	BranchesCount  int
+	LastCommitDate time.Time `json:"last_commit_date"`
+	OpenPRs        int       `json:"-"`
+	OpenIssues     int       `json:"-"`
+	BranchesCount  int       `json:"-"`
 }
 
-// CIStatus represents CI/CD status
+// CIStatus represents CI/CD status. The build and test statuses are guessed
+// from the CI configuration until they are fetched from the CI service, so
+// they are left out of JSON output.
 type CIStatus struct {
-	HasCI           bool
-	LastBuildStatus string
-	LastTestStatus  string
+	HasCI           bool   `json:"has_ci"`
+	LastBuildStatus string `json:"-"`
+	LastTestStatus  string `json:"-"`
 }
 
 // ScanProjectHealth performs a comprehensive health check of the project
 func ScanProjectHealth(projectPath string) HealthStatus {
+	return ScanProjectHealthContext(context.Background(), projectPath)
+}
+
+// ScanProjectHealthContext is ScanProjectHealth with a deadline for its git
+// commands. The git metrics are incomplete when ctx is done first.
+func ScanProjectHealthContext(ctx context.Context, projectPath string) HealthStatus {
 	return HealthStatus{
 		DependencyStatus: scanDependencies(projectPath),
-		GitMetrics:       scanGitMetrics(projectPath),
+		GitMetrics:       scanGitMetrics(ctx, projectPath),
 		CIStatus:         scanCIStatus(projectPath),
 		LastScanTime:     time.Now(),
 	}
 }
 
+// packageFiles maps manifest files to their package manager, in the order
+// they are checked so projects with several manifests get a stable answer
+var packageFiles = []struct {
+	File    string
+	Manager string
+}{
+	{"go.mod", "go"},
+	{"Cargo.toml", "cargo"},
+	{"pom.xml", "maven"},
+	{"build.gradle", "gradle"},
+	{"build.gradle.kts", "gradle"},
+	{"pyproject.toml", "pip"},
+	{"requirements.txt", "pip"},
+	{"Gemfile", "bundler"},
+	{"package.json", "npm"},
+}
+
+// DetectPackageManager returns the package manager of the project from its
+// manifest file, or an empty string if none is found
+func DetectPackageManager(projectPath string) string {
+	for _, pf := range packageFiles {
+		if _, err := os.Stat(filepath.Join(projectPath, pf.File)); err == nil {
+			return pf.Manager
+		}
+	}
+	return ""
+}
+
 // scanDependencies checks project dependencies
 func scanDependencies(projectPath string) DependencyStatus {
 	status := DependencyStatus{}
 
-	// Check for common package manager files
-	packageFiles := map[string]string{
-		"package.json":     "npm",
-		"go.mod":           "go",
-		"requirements.txt": "pip",
-		"Gemfile":          "bundler",
-		"pom.xml":          "maven",
-		"build.gradle":     "gradle",
-		"Cargo.toml":       "cargo",
-	}
-
-	for file, manager := range packageFiles {
-		if _, err := os.Stat(filepath.Join(projectPath, file)); err == nil {
-			status.PackageManager = manager
-			break
-		}
-	}
+	status.PackageManager = DetectPackageManager(projectPath)
 
 	// Check for lock files
 	lockFiles := map[string]bool{
@@ -154,8 +177,8 @@ func scanDependencies(projectPath string) DependencyStatus {
 }
 
 // scanGitMetrics collects Git-related metrics
-func scanGitMetrics(projectPath string) GitMetrics {
-	gitInfo := fs.CheckGitStatus(projectPath)
+func scanGitMetrics(ctx context.Context, projectPath string) GitMetrics {
+	gitInfo := fs.CheckGitRepoContext(ctx, projectPath)
 	metrics := GitMetrics{}
 
 	if !gitInfo.HasGit {
@@ -163,7 +186,7 @@ func scanGitMetrics(projectPath string) GitMetrics {
 	}
 
 	// Get last commit date
-	lastCommitCmd := exec.Command("git", "log", "-1", "--format=%cd", "--date=format:%Y-%m-%d")
+	lastCommitCmd := exec.CommandContext(ctx, "git", "log", "-1", "--format=%cd", "--date=format:%Y-%m-%d")
 	lastCommitCmd.Dir = projectPath
 	output, err := lastCommitCmd.Output()
 	if err == nil && len(output) > 0 {
@@ -283,23 +306,72 @@ func scanCIStatus(projectPath string) CIStatus {
 	return status
 }
 
+// Overall health levels of a project, from best to worst
+const (
+	Healthy  = "healthy"
+	Warning  = "warning"
+	Critical = "critical"
+)
+
+// levelOrder ranks the health levels from best to worst
+var levelOrder = map[string]int{Healthy: 0, Warning: 1, Critical: 2}
+
+// LevelStyle returns the style a health level is rendered in
+func LevelStyle(level string) lipgloss.Style {
+	colors := map[string]string{
+		Healthy:  "#A8CC8C",
+		Warning:  "#FFB86C",
+		Critical: "#FF5555",
+	}
+	return lipgloss.NewStyle().Foreground(lipgloss.Color(colors[level]))
+}
+
+// Level rates the dependencies: vulnerabilities are critical, outdated
+// dependencies and a missing lock file are warnings
+func (s DependencyStatus) Level() string {
+	switch {
+	case s.Vulnerabilities > 0:
+		return Critical
+	case s.OutdatedDeps > 0, !s.HasLockFile:
+		return Warning
+	}
+	return Healthy
+}
+
+// Level rates the CI setup: a project without CI is a warning. With CI,
+// a successful build and test run is healthy, only one of them a warning
+// and neither critical.
+func (s CIStatus) Level() string {
+	if !s.HasCI {
+		return Warning
+	}
+
+	build, test := s.LastBuildStatus == "Success", s.LastTestStatus == "Success"
+	switch {
+	case build && test:
+		return Healthy
+	case build || test:
+		return Warning
+	}
+	return Critical
+}
+
+// Level sums up the health status as the worst level of its parts, which
+// RenderHealthStatus shows in the same colors
+func (s HealthStatus) Level() string {
+	level := s.DependencyStatus.Level()
+	if ci := s.CIStatus.Level(); levelOrder[ci] > levelOrder[level] {
+		level = ci
+	}
+	return level
+}
+
 // RenderHealthStatus returns a formatted string representation of health status
 func RenderHealthStatus(status HealthStatus) string {
 	var b strings.Builder
 
-	// Define colors
-	healthyColor := lipgloss.Color("#A8CC8C")
-	warningColor := lipgloss.Color("#FFB86C")
-	criticalColor := lipgloss.Color("#FF5555")
-
 	// Render dependency status
-	depStyle := lipgloss.NewStyle().Foreground(healthyColor)
-	if status.DependencyStatus.OutdatedDeps > 0 {
-		depStyle = lipgloss.NewStyle().Foreground(warningColor)
-	}
-	if status.DependencyStatus.Vulnerabilities > 0 {
-		depStyle = lipgloss.NewStyle().Foreground(criticalColor)
-	}
+	depStyle := LevelStyle(status.DependencyStatus.Level())
 
 	b.WriteString("Dependencies: " + depStyle.Render(status.DependencyStatus.PackageManager) + "\n")
 	if status.DependencyStatus.HasLockFile {
@@ -315,10 +387,7 @@ func RenderHealthStatus(status HealthStatus) string {
 	b.WriteString(fmt.Sprintf("  Open Issues: %d\n", status.GitMetrics.OpenIssues))
 
 	// Render CI status
-	ciStyle := lipgloss.NewStyle().Foreground(criticalColor)
-	if status.CIStatus.HasCI {
-		ciStyle = lipgloss.NewStyle().Foreground(healthyColor)
-	}
+	ciStyle := LevelStyle(status.CIStatus.Level())
 
 	b.WriteString("\nCI/CD Status: " + ciStyle.Render(status.CIStatus.LastBuildStatus) + "\n")
 
@@ -0,0 +1,309 @@
+package launch
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"text/template"
+
+	"mpm/pkg/config"
+	"mpm/pkg/health"
+	"mpm/pkg/shell"
+)
+
+// Mode decides how a launcher runs
+type Mode string
+
+const (
+	// Detach starts the program in the background and returns at once,
+	// for GUI editors and file managers
+	Detach Mode = "detach"
+	// Foreground runs the program attached to the terminal and waits for it,
+	// for terminal editors
+	Foreground Mode = "foreground"
+	// Shell hands the command to the calling shell through the wrapper from
+	// `mpm init`, so it runs in the project directory after mpm exits
+	Shell Mode = "shell"
+)
+
+// Launcher opens a project directory with an external program
+type Launcher struct {
+	Name    string   // Identifier used by `mpm open --with`
+	Key     string   // Key in the action view of interactive mode
+	Label   string   // Human readable name
+	Command []string // Program and arguments, as text/templates over config.Project
+	Mode    Mode
+}
+
+// Builtin lists the launchers available out of the box
+var Builtin = []Launcher{
+	{Name: "code", Key: "v", Label: "VS Code", Command: []string{"code", "{{.Path}}"}, Mode: Detach},
+	{Name: "zed", Key: "z", Label: "Zed", Command: []string{"zed", "{{.Path}}"}, Mode: Detach},
+	{Name: "cursor", Key: "c", Label: "Cursor", Command: []string{"cursor", "{{.Path}}"}, Mode: Detach},
+	{Name: "nvim", Key: "n", Label: "Neovim", Command: []string{"nvim", "."}, Mode: Shell},
+	{Name: "finder", Key: "f", Label: "Finder/File Explorer", Command: []string{fileManager(), "{{.Path}}"}, Mode: Detach},
+	{Name: "subl", Key: "s", Label: "Sublime Text", Command: []string{"subl", "{{.Path}}"}, Mode: Detach},
+	{Name: "trae", Key: "t", Label: "Trae", Command: []string{"trae", "{{.Path}}"}, Mode: Detach},
+	{Name: "mate", Key: "m", Label: "TextMate", Command: []string{"mate", "{{.Path}}"}, Mode: Detach},
+	{Name: "idea", Key: "i", Label: "IntelliJ IDEA", Command: []string{"idea", "{{.Path}}"}, Mode: Detach},
+	{Name: "pycharm", Key: "p", Label: "PyCharm", Command: []string{"pycharm", "{{.Path}}"}, Mode: Detach},
+	{Name: "goland", Key: "o", Label: "GoLand", Command: []string{"goland", "{{.Path}}"}, Mode: Detach},
+}
+
+// ReservedKeys are taken by the action view itself
+var ReservedKeys = []string{"g", "enter", "e", "d", "T", "esc", "q", "up", "down", "pgup", "pgdown", "home", "end", "ctrl+c",
+	"1", "2", "3", "4", "5", "6", "7", "8", "9"}
+
+// fileManager returns the program that opens a directory in the
+// platform's file manager
+func fileManager() string {
+	switch runtime.GOOS {
+	case "darwin":
+		return "open"
+	case "windows":
+		return "explorer"
+	default: // Linux
+		return "xdg-open"
+	}
+}
+
+// Registry returns the built-in launchers combined with the ones from the
+// config file. Config entries named like a built-in override the fields
+// they set, disabled entries are dropped and the rest are appended.
+func Registry(cfg config.Config) ([]Launcher, error) {
+	launchers := append([]Launcher{}, Builtin...)
+
+	for _, entry := range cfg.Launchers {
+		i := indexOf(launchers, entry.Name)
+		if entry.Disabled {
+			if i >= 0 {
+				launchers = append(launchers[:i], launchers[i+1:]...)
+			}
+			continue
+		}
+
+		l := Launcher{Name: entry.Name, Label: entry.Name, Mode: Detach}
+		if i >= 0 {
+			l = launchers[i]
+		}
+		if entry.Key != "" {
+			l.Key = entry.Key
+		}
+		if entry.Label != "" {
+			l.Label = entry.Label
+		}
+		if len(entry.Command) > 0 {
+			l.Command = entry.Command
+		}
+		if entry.Mode != "" {
+			l.Mode = Mode(entry.Mode)
+		}
+
+		if len(l.Command) == 0 {
+			return nil, fmt.Errorf("launcher '%s' has no command", l.Name)
+		}
+		if l.Mode != Detach && l.Mode != Foreground && l.Mode != Shell {
+			return nil, fmt.Errorf("launcher '%s' has invalid mode '%s' (use %s)", l.Name, l.Mode, strings.Join(config.LauncherModes, ", "))
+		}
+		if i >= 0 {
+			launchers[i] = l
+		} else {
+			launchers = append(launchers, l)
+		}
+	}
+
+	// Every key must pick exactly one action
+	keys := make(map[string]string)
+	for _, l := range launchers {
+		if l.Key == "" {
+			continue
+		}
+		for _, reserved := range ReservedKeys {
+			if l.Key == reserved {
+				return nil, fmt.Errorf("launcher '%s' uses key '%s', which is reserved", l.Name, l.Key)
+			}
+		}
+		if other, ok := keys[l.Key]; ok {
+			return nil, fmt.Errorf("launchers '%s' and '%s' both use key '%s'", other, l.Name, l.Key)
+		}
+		keys[l.Key] = l.Name
+	}
+
+	return launchers, nil
+}
+
+// indexOf returns the position of the launcher with the given name, or -1
+func indexOf(launchers []Launcher, name string) int {
+	for i, l := range launchers {
+		if strings.EqualFold(l.Name, name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// Find returns the launcher with the given name
+func Find(launchers []Launcher, name string) (Launcher, bool) {
+	if i := indexOf(launchers, name); i >= 0 {
+		return launchers[i], true
+	}
+	return Launcher{}, false
+}
+
+// Names returns the names of the given launchers
+func Names(launchers []Launcher) []string {
+	names := make([]string, len(launchers))
+	for i, l := range launchers {
+		names[i] = l.Name
+	}
+	return names
+}
+
+// Editor returns a foreground launcher for $VISUAL or $EDITOR, if set
+func Editor() (Launcher, bool) {
+	for _, env := range []string{"VISUAL", "EDITOR"} {
+		if fields := strings.Fields(os.Getenv(env)); len(fields) > 0 {
+			return Launcher{
+				Name:    fields[0],
+				Label:   "$" + env,
+				Command: append(fields, "."),
+				Mode:    Foreground,
+			}, true
+		}
+	}
+	return Launcher{}, false
+}
+
+// Args renders the launcher's command line for a project
+func (l Launcher) Args(project config.Project) ([]string, error) {
+	args := make([]string, len(l.Command))
+	for i, arg := range l.Command {
+		tmpl, err := template.New(l.Name).Parse(arg)
+		if err != nil {
+			return nil, fmt.Errorf("launcher '%s' has an invalid command: %w", l.Name, err)
+		}
+
+		var b strings.Builder
+		if err := tmpl.Execute(&b, project); err != nil {
+			return nil, fmt.Errorf("launcher '%s' has an invalid command: %w", l.Name, err)
+		}
+		args[i] = b.String()
+	}
+	return args, nil
+}
+
+// Installed reports whether the launcher's program can be found
+func (l Launcher) Installed() bool {
+	if len(l.Command) == 0 {
+		return false
+	}
+	// A templated program name can only be checked when it is used
+	if strings.Contains(l.Command[0], "{{") {
+		return true
+	}
+	_, err := exec.LookPath(l.Command[0])
+	return err == nil
+}
+
+// ShellCommand returns the command the calling shell runs for Shell mode
+func (l Launcher) ShellCommand(project config.Project) (string, error) {
+	args, err := l.Args(project)
+	if err != nil {
+		return "", err
+	}
+	for i, arg := range args {
+		args[i] = shell.Quote(arg)
+	}
+	return shell.CdCommand(project.Path, strings.Join(args, " ")), nil
+}
+
+// Cmd returns the process that opens a project, running in the project
+// directory. It fails if the program is not installed.
+func (l Launcher) Cmd(project config.Project) (*exec.Cmd, error) {
+	args, err := l.Args(project)
+	if err != nil {
+		return nil, err
+	}
+	if len(args) == 0 {
+		return nil, fmt.Errorf("launcher '%s' has no command", l.Name)
+	}
+
+	program, err := exec.LookPath(args[0])
+	if err != nil {
+		return nil, fmt.Errorf("%s is not installed ('%s' not found in PATH)", l.Label, args[0])
+	}
+
+	cmd := exec.Command(program, args[1:]...)
+	cmd.Dir = project.Path
+	return cmd, nil
+}
+
+// Run opens a project with the launcher. Detached programs are started and
+// left running; foreground programs run attached to the terminal until they
+// exit. Shell mode launchers cannot be run directly, use ShellCommand.
+func (l Launcher) Run(project config.Project) error {
+	if l.Mode == Shell {
+		return fmt.Errorf("%s must be started by the shell, use ShellCommand", l.Label)
+	}
+
+	cmd, err := l.Cmd(project)
+	if err != nil {
+		return err
+	}
+
+	if l.Mode == Foreground {
+		cmd.Stdin = os.Stdin
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("running %s: %w", l.Label, err)
+		}
+		return nil
+	}
+
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("starting %s: %w", l.Label, err)
+	}
+	// Don't wait for the program, it outlives mpm
+	return cmd.Process.Release()
+}
+
+// smartDefaults maps package managers to the IDE preferred for them
+var smartDefaults = map[string]string{
+	"go":     "goland",
+	"pip":    "pycharm",
+	"maven":  "idea",
+	"gradle": "idea",
+}
+
+// Preferred returns the launcher a project opens with by default: the
+// project's own choice, then its category's, then an installed IDE matching
+// the project type, then $VISUAL or $EDITOR, and finally VS Code
+func Preferred(launchers []Launcher, categoryLaunchers map[string]string, project config.Project) (Launcher, error) {
+	for _, name := range []string{project.Launcher, categoryLaunchers[project.Category]} {
+		if name == "" {
+			continue
+		}
+		if l, ok := Find(launchers, name); ok {
+			return l, nil
+		}
+		return Launcher{}, fmt.Errorf("project '%s' prefers unknown launcher '%s'", project.Name, name)
+	}
+
+	if name, ok := smartDefaults[health.DetectPackageManager(project.Path)]; ok {
+		if l, ok := Find(launchers, name); ok && l.Installed() {
+			return l, nil
+		}
+	}
+
+	if editor, ok := Editor(); ok {
+		return editor, nil
+	}
+
+	if l, ok := Find(launchers, "code"); ok {
+		return l, nil
+	}
+	return Launcher{}, fmt.Errorf("no launcher available for project '%s'", project.Name)
+}
@@ -0,0 +1,60 @@
+package shell
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Environment variables set by the shell wrapper from `mpm init`
+const (
+	// CdFileEnv names a private file, created by the wrapper, that receives
+	// the command to run in the calling shell
+	CdFileEnv = "MPM_CD_FILE"
+	// ShellEnv names the shell the wrapper runs in, which decides quoting
+	ShellEnv = "MPM_SHELL"
+)
+
+// Quote quotes s as a single word for the shell the wrapper runs in, so
+// paths with spaces, quotes or $(...) are never expanded
+func Quote(s string) string {
+	if os.Getenv(ShellEnv) == "fish" {
+		// fish treats \ and ' as escapes inside single quotes
+		r := strings.NewReplacer(`\`, `\\`, `'`, `\'`)
+		return "'" + r.Replace(s) + "'"
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// CdCommand returns the command that changes the calling shell to dir and
+// then runs then, if given
+func CdCommand(dir, then string) string {
+	command := "cd " + Quote(dir)
+	if then != "" {
+		command += " && " + then
+	}
+	return command
+}
+
+// Handoff passes a command to the calling shell. With the wrapper from
+// `mpm init` it is written to the file named by MPM_CD_FILE, which must
+// already exist; otherwise it is printed on stdout for older wrappers.
+func Handoff(command string) error {
+	path := os.Getenv(CdFileEnv)
+	if path == "" {
+		fmt.Println(command)
+		return nil
+	}
+
+	// Never create the file: only a file the wrapper made privately with
+	// mktemp is trusted
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_TRUNC, 0)
+	if err != nil {
+		return fmt.Errorf("writing %s: %w", CdFileEnv, err)
+	}
+	if _, err := fmt.Fprintln(f, command); err != nil {
+		f.Close()
+		return fmt.Errorf("writing %s: %w", CdFileEnv, err)
+	}
+	return f.Close()
+}
@@ -0,0 +1,117 @@
+package shell
+
+import (
+	"os/exec"
+	"testing"
+)
+
+// hostileWords are paths that must reach the shell as one literal word
+var hostileWords = []string{
+	"/code/api",
+	"/code/my project",
+	"/code/it's",
+	"/code/$(touch pwned)",
+	"/code/`touch pwned`",
+	"/code/$HOME",
+	"/code/*",
+	"~/code",
+	"/code/line\nbreak",
+	`/code/back\slash`,
+	`/code/\'`,
+	`/code/'\''`,
+	"/code/;rm -rf x",
+	"/code/!bang",
+	"",
+}
+
+func TestQuoteGolden(t *testing.T) {
+	tests := []struct {
+		shell string
+		in    string
+		want  string
+	}{
+		{"bash", "/code/my project", `'/code/my project'`},
+		{"bash", "/code/it's", `'/code/it'\''s'`},
+		{"zsh", `/code/back\slash`, `'/code/back\slash'`},
+		{"fish", "/code/it's", `'/code/it\'s'`},
+		{"fish", `/code/back\slash`, `'/code/back\\slash'`},
+		{"fish", "/code/$(touch pwned)", `'/code/$(touch pwned)'`},
+	}
+
+	for _, tt := range tests {
+		t.Setenv(ShellEnv, tt.shell)
+		if got := Quote(tt.in); got != tt.want {
+			t.Errorf("%s: Quote(%q) = %s, want %s", tt.shell, tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestQuoteRoundTrip(t *testing.T) {
+	shells := []struct {
+		name string
+		args []string // Run a command without reading any startup files
+	}{
+		{"bash", []string{"--norc", "--noprofile", "-c"}},
+		{"zsh", []string{"-f", "-c"}},
+		{"fish", []string{"--no-config", "-c"}},
+		{"sh", []string{"-c"}},
+	}
+
+	for _, sh := range shells {
+		t.Run(sh.name, func(t *testing.T) {
+			path, err := exec.LookPath(sh.name)
+			if err != nil {
+				t.Skipf("%s is not installed", sh.name)
+			}
+			t.Setenv(ShellEnv, sh.name)
+
+			for _, word := range hostileWords {
+				// printf prints its argument back only if it arrived as
+				// one word; the brackets show stray splitting
+				script := "printf '[%s]' " + Quote(word)
+				out, err := exec.Command(path, append(sh.args, script)...).Output()
+				if err != nil {
+					t.Errorf("%q: %v", word, err)
+					continue
+				}
+				if got := string(out); got != "["+word+"]" {
+					t.Errorf("%q came back as %q", word, got)
+				}
+			}
+		})
+	}
+}
+
+// fishUnquote reads back a word in fish single quotes, where \\ and \' are
+// the only escapes. It reports false if s is not exactly one such word.
+func fishUnquote(s string) (string, bool) {
+	if len(s) < 2 || s[0] != '\'' {
+		return "", false
+	}
+
+	var out []byte
+	for i := 1; i < len(s); i++ {
+		switch {
+		case s[i] == '\\' && i+1 < len(s) && (s[i+1] == '\\' || s[i+1] == '\''):
+			out = append(out, s[i+1])
+			i++
+		case s[i] == '\'':
+			// The closing quote has to end the word
+			return string(out), i == len(s)-1
+		default:
+			out = append(out, s[i])
+		}
+	}
+	return "", false
+}
+
+// TestQuoteFishRules checks fish quoting without fish installed
+func TestQuoteFishRules(t *testing.T) {
+	t.Setenv(ShellEnv, "fish")
+	for _, word := range hostileWords {
+		quoted := Quote(word)
+		if got, ok := fishUnquote(quoted); !ok || got != word {
+			t.Errorf("%q quoted as %s reads back as %q", word, quoted, got)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package shell
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+)
+
+//go:embed scripts/*.tmpl
+var scripts embed.FS
+
+// Shells lists the shells an integration script can be generated for
+var Shells = []string{"bash", "zsh", "fish"}
+
+// Options customizes the generated shell integration
+type Options struct {
+	Alias string // Short command for `mpm go`, e.g. "j"; empty for none
+	Key   string // Key that opens interactive mode, e.g. "ctrl-g"; empty for none
+}
+
+// aliasPattern restricts aliases to names that are valid shell functions
+var aliasPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+
+// keyPattern accepts ctrl-<letter> key specifications
+var keyPattern = regexp.MustCompile(`^ctrl-([a-z])$`)
+
+// templateData is passed to the shell script templates
+type templateData struct {
+	Alias string
+	Key   string // Key already converted to the shell's own notation
+}
+
+// Script returns the integration script for the given shell
+func Script(shell string, opts Options) (string, error) {
+	data := templateData{Alias: opts.Alias}
+
+	if opts.Alias != "" && !aliasPattern.MatchString(opts.Alias) {
+		return "", fmt.Errorf("invalid alias '%s'", opts.Alias)
+	}
+
+	if opts.Key != "" {
+		match := keyPattern.FindStringSubmatch(strings.ToLower(opts.Key))
+		if match == nil {
+			return "", fmt.Errorf("invalid key '%s' (use ctrl-<letter>, e.g. ctrl-g)", opts.Key)
+		}
+		data.Key = keyNotation(shell, match[1])
+	}
+
+	source, err := scripts.ReadFile("scripts/" + shell + ".tmpl")
+	if err != nil {
+		return "", fmt.Errorf("unsupported shell '%s' (supported: %s)", shell, strings.Join(Shells, ", "))
+	}
+
+	tmpl, err := template.New(shell).Parse(string(source))
+	if err != nil {
+		return "", err
+	}
+
+	var b bytes.Buffer
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// keyNotation converts a ctrl+letter key to the shell's binding syntax
+func keyNotation(shell, letter string) string {
+	switch shell {
+	case "bash":
+		return `\C-` + letter
+	case "zsh":
+		return "^" + strings.ToUpper(letter)
+	default:
+		return `\c` + letter
+	}
+}
@@ -0,0 +1,68 @@
+package shell
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fakeMpm reports whether the wrapper set up a directory handoff
+const fakeMpm = `#!/bin/sh
+if [ -n "$MPM_CD_FILE" ]; then echo handoff; else echo direct; fi
+`
+
+func TestScriptFindsCommand(t *testing.T) {
+	bin := t.TempDir()
+	if err := os.WriteFile(filepath.Join(bin, "mpm"), []byte(fakeMpm), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	tests := []struct {
+		args string
+		want string
+	}{
+		{"go api", "handoff"},
+		{"--config /tmp/mpm.json go api", "handoff"},
+		{"--config=/tmp/mpm.json i", "handoff"},
+		{"--config /tmp/mpm.json open api --with nvim", "handoff"},
+		{"--config go list", "direct"},
+		{"list --sort used", "direct"},
+		{"--help", "direct"},
+	}
+
+	shells := []struct {
+		name string
+		args []string // Run a command without reading any startup files
+	}{
+		{"bash", []string{"--norc", "--noprofile", "-c"}},
+		{"zsh", []string{"-f", "-c"}},
+		{"fish", []string{"--no-config", "-c"}},
+	}
+
+	for _, sh := range shells {
+		t.Run(sh.name, func(t *testing.T) {
+			path, err := exec.LookPath(sh.name)
+			if err != nil {
+				t.Skipf("%s is not installed", sh.name)
+			}
+			script, err := Script(sh.name, Options{})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, tt := range tests {
+				out, err := exec.Command(path, append(sh.args, script+"\nmpm "+tt.args)...).Output()
+				if err != nil {
+					t.Errorf("mpm %s: %v", tt.args, err)
+					continue
+				}
+				if got := strings.TrimSpace(string(out)); got != tt.want {
+					t.Errorf("mpm %s: got %s, want %s", tt.args, got, tt.want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,291 @@
+package task
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"mpm/pkg/config"
+)
+
+// LocalFile is the repo-local file that defines tasks next to the code
+const LocalFile = ".mpm.json"
+
+// Task is a named command run in a project directory
+type Task struct {
+	Name    string `json:"name"`
+	Command string `json:"command"` // Run by the shell in the project directory
+	Source  string `json:"source"`  // Where the task is defined, e.g. "config" or "Makefile"
+}
+
+// Discover returns the tasks of a project: the ones from its config entry,
+// then the repo-local .mpm.json, then targets and scripts found in its
+// Makefile, package.json, justfile and Taskfile.yml. When several sources
+// define the same name the first one wins. Only an unreadable .mpm.json is
+// an error; build files that cannot be parsed are skipped.
+func Discover(project config.Project) ([]Task, error) {
+	var tasks []Task
+	seen := make(map[string]bool)
+	add := func(found []Task) {
+		for _, t := range found {
+			if !seen[t.Name] {
+				seen[t.Name] = true
+				tasks = append(tasks, t)
+			}
+		}
+	}
+
+	add(fromMap(project.Tasks, "config"))
+
+	local, err := fromLocalFile(project.Path)
+	if err != nil {
+		return nil, err
+	}
+	add(local)
+
+	add(fromMakefile(project.Path))
+	add(fromPackageJSON(project.Path))
+	add(fromJustfile(project.Path))
+	add(fromTaskfile(project.Path))
+
+	return tasks, nil
+}
+
+// Find returns the task with the given name
+func Find(tasks []Task, name string) (Task, bool) {
+	for _, t := range tasks {
+		if t.Name == name {
+			return t, true
+		}
+	}
+	return Task{}, false
+}
+
+// Names returns the names of the given tasks
+func Names(tasks []Task) []string {
+	names := make([]string, len(tasks))
+	for i, t := range tasks {
+		names[i] = t.Name
+	}
+	return names
+}
+
+// Cmd returns the process that runs the task in dir, with args appended to
+// its command line
+func (t Task) Cmd(dir string, args []string) *exec.Cmd {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", append([]string{"/C", t.Command}, args...)...)
+	} else {
+		// "$@" passes the extra arguments without another round of quoting
+		command := t.Command
+		if len(args) > 0 {
+			command += ` "$@"`
+		}
+		cmd = exec.Command("sh", append([]string{"-c", command, t.Name}, args...)...)
+	}
+	cmd.Dir = dir
+	return cmd
+}
+
+// fromMap turns a name to command map into tasks sorted by name
+func fromMap(commands map[string]string, source string) []Task {
+	tasks := make([]Task, 0, len(commands))
+	for name, command := range commands {
+		tasks = append(tasks, Task{Name: name, Command: command, Source: source})
+	}
+	sort.Slice(tasks, func(i, j int) bool { return tasks[i].Name < tasks[j].Name })
+	return tasks
+}
+
+// fromLocalFile reads the "tasks" object of the repo-local .mpm.json
+func fromLocalFile(dir string) ([]Task, error) {
+	data, err := os.ReadFile(filepath.Join(dir, LocalFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", LocalFile, err)
+	}
+
+	var local struct {
+		Tasks map[string]string `json:"tasks"`
+	}
+	if err := json.Unmarshal(data, &local); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", filepath.Join(dir, LocalFile), err)
+	}
+	return fromMap(local.Tasks, LocalFile), nil
+}
+
+// makeTarget matches a rule line such as "build: deps" or "clean::", but
+// not variable assignments like "CC := gcc" or "CFLAGS ::= -O2"
+var makeTarget = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9_./-]*)\s*::?([^:=]|$)`)
+
+// makePhony matches a .PHONY declaration and captures its targets
+var makePhony = regexp.MustCompile(`^\.PHONY\s*:(.*)`)
+
+// fromMakefile lists the explicit targets of the Makefile, skipping
+// special targets like .PHONY and pattern rules. Targets with a / or . in
+// their name are usually files the Makefile builds, so they are only
+// listed when declared .PHONY.
+func fromMakefile(dir string) []Task {
+	for _, name := range []string{"GNUmakefile", "makefile", "Makefile"} {
+		lines, ok := readLines(filepath.Join(dir, name))
+		if !ok {
+			continue
+		}
+
+		phony := make(map[string]bool)
+		for _, line := range lines {
+			if m := makePhony.FindStringSubmatch(line); m != nil {
+				for _, target := range strings.Fields(m[1]) {
+					phony[target] = true
+				}
+			}
+		}
+
+		var tasks []Task
+		for _, line := range lines {
+			m := makeTarget.FindStringSubmatch(line)
+			if m == nil {
+				continue
+			}
+			if strings.ContainsAny(m[1], "/.") && !phony[m[1]] {
+				continue
+			}
+			tasks = append(tasks, Task{Name: m[1], Command: "make " + m[1], Source: name})
+		}
+		return tasks
+	}
+	return nil
+}
+
+// fromPackageJSON lists the scripts of package.json, run with the package
+// manager whose lock file is present
+func fromPackageJSON(dir string) []Task {
+	data, err := os.ReadFile(filepath.Join(dir, "package.json"))
+	if err != nil {
+		return nil
+	}
+
+	var pkg struct {
+		Scripts map[string]string `json:"scripts"`
+	}
+	if json.Unmarshal(data, &pkg) != nil {
+		return nil
+	}
+
+	runner := "npm run"
+	for _, lock := range []struct{ file, runner string }{
+		{"pnpm-lock.yaml", "pnpm run"},
+		{"yarn.lock", "yarn run"},
+		{"bun.lockb", "bun run"},
+		{"bun.lock", "bun run"},
+	} {
+		if _, err := os.Stat(filepath.Join(dir, lock.file)); err == nil {
+			runner = lock.runner
+			break
+		}
+	}
+
+	commands := make(map[string]string, len(pkg.Scripts))
+	for name := range pkg.Scripts {
+		commands[name] = runner + " " + name
+	}
+	return fromMap(commands, "package.json")
+}
+
+// justRecipe matches a recipe header such as "test arg='x':" or "@build:",
+// but not assignments like "version := '1'"
+var justRecipe = regexp.MustCompile(`^@?([A-Za-z_][A-Za-z0-9_-]*)(\s+[^:]*)?:([^=]|$)`)
+
+// justKeywords start justfile lines that are not recipes
+var justKeywords = []string{"alias ", "export ", "import ", "mod ", "set "}
+
+// fromJustfile lists the public recipes of the justfile
+func fromJustfile(dir string) []Task {
+	for _, name := range []string{"justfile", "Justfile", ".justfile"} {
+		lines, ok := readLines(filepath.Join(dir, name))
+		if !ok {
+			continue
+		}
+
+		var tasks []Task
+	lines:
+		for _, line := range lines {
+			for _, keyword := range justKeywords {
+				if strings.HasPrefix(line, keyword) {
+					continue lines
+				}
+			}
+			// Recipes starting with _ are private
+			if m := justRecipe.FindStringSubmatch(line); m != nil && !strings.HasPrefix(m[1], "_") {
+				tasks = append(tasks, Task{Name: m[1], Command: "just " + m[1], Source: name})
+			}
+		}
+		return tasks
+	}
+	return nil
+}
+
+// fromTaskfile lists the tasks of a Taskfile in the order they are
+// defined, skipping the ones marked internal
+func fromTaskfile(dir string) []Task {
+	for _, name := range []string{"Taskfile.yml", "Taskfile.yaml", "taskfile.yml", "taskfile.yaml"} {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			continue
+		}
+
+		// A node keeps the tasks in file order, unlike a map
+		var taskfile struct {
+			Tasks yaml.Node `yaml:"tasks"`
+		}
+		if yaml.Unmarshal(data, &taskfile) != nil || taskfile.Tasks.Kind != yaml.MappingNode {
+			return nil
+		}
+
+		var tasks []Task
+		entries := taskfile.Tasks.Content
+		for i := 0; i+1 < len(entries); i += 2 {
+			var task struct {
+				Internal bool `yaml:"internal"`
+			}
+			// Tasks can be a bare command string or list, never internal
+			entries[i+1].Decode(&task)
+			if task.Internal {
+				continue
+			}
+
+			taskName := entries[i].Value
+			tasks = append(tasks, Task{Name: taskName, Command: "task " + taskName, Source: name})
+		}
+		return tasks
+	}
+	return nil
+}
+
+// readLines returns the lines of a file, or false if it cannot be read
+func readLines(path string) ([]string, bool) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, false
+	}
+	defer f.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	return lines, scanner.Err() == nil
+}
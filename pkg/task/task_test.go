@@ -0,0 +1,162 @@
+package task
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// writeFile creates a build file in a fresh project directory
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestFromMakefile(t *testing.T) {
+	tests := []struct {
+		name     string
+		makefile string
+		want     []string
+	}{
+		{
+			name:     "rules",
+			makefile: "build: deps\n\tgo build\n\ndeps:\n\ttest:\nclean::\n",
+			want:     []string{"build", "deps", "clean"},
+		},
+		{
+			name:     "assignments",
+			makefile: "CC := gcc\nFLAGS ::= -O2\nLDFLAGS=-s\nall: bin\n",
+			want:     []string{"all"},
+		},
+		{
+			name:     "special and pattern rules",
+			makefile: ".PHONY: all\n.DEFAULT_GOAL := all\n%.o: %.c\nall:\n",
+			want:     []string{"all"},
+		},
+		{
+			name:     "file targets",
+			makefile: "bin/mpm: main.go\nmpm.1: docs\ndocs/site:\nrelease:\n",
+			want:     []string{"release"},
+		},
+		{
+			name:     "phony file-like targets",
+			makefile: ".PHONY: docs/site lint.fix\n.PHONY : test.unit\ndocs/site:\nlint.fix:\ntest.unit:\nbin/mpm:\n",
+			want:     []string{"docs/site", "lint.fix", "test.unit"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeFile(t, "Makefile", tt.makefile)
+			if got := Names(fromMakefile(dir)); !slices.Equal(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFromTaskfile(t *testing.T) {
+	tests := []struct {
+		name     string
+		taskfile string
+		want     []string
+	}{
+		{
+			name: "file order",
+			taskfile: `version: '3'
+tasks:
+  test:
+    cmds: [go test ./...]
+  build:
+    cmds:
+      - go build
+  'docs:serve':
+    cmds: [mkdocs serve]
+`,
+			want: []string{"test", "build", "docs:serve"},
+		},
+		{
+			name: "short forms and internal tasks",
+			taskfile: `tasks:
+  lint: golangci-lint run
+  fmt:
+    - gofmt -w .
+  setup:
+    internal: true
+    cmds: [go mod download]
+`,
+			want: []string{"lint", "fmt"},
+		},
+		{
+			name: "nested keys and other sections",
+			taskfile: `vars:
+  GREETING: hello
+tasks:
+    deploy:
+        deps: [build]
+        env:
+            STAGE: prod
+includes:
+  docs: ./docs
+`,
+			want: []string{"deploy"},
+		},
+		{
+			name:     "flow style",
+			taskfile: `{version: '3', tasks: {a: {cmds: [echo a]}, b: echo b}}`,
+			want:     []string{"a", "b"},
+		},
+		{
+			name:     "no tasks",
+			taskfile: "version: '3'\n",
+			want:     nil,
+		},
+		{
+			name:     "invalid",
+			taskfile: "tasks: [unclosed\n",
+			want:     nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeFile(t, "Taskfile.yml", tt.taskfile)
+			if got := Names(fromTaskfile(dir)); !slices.Equal(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFromJustfile(t *testing.T) {
+	tests := []struct {
+		name     string
+		justfile string
+		want     []string
+	}{
+		{
+			name:     "recipes",
+			justfile: "build:\n    go build\n\ntest arg='./...':\n    go test {{arg}}\n\n@quiet:\n    true\n",
+			want:     []string{"build", "test", "quiet"},
+		},
+		{
+			name:     "private recipes and settings",
+			justfile: "set shell := [\"bash\", \"-c\"]\nalias b := build\nversion := '1'\n_helper:\n    true\nbuild: _helper\n    go build\n",
+			want:     []string{"build"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeFile(t, "justfile", tt.justfile)
+			if got := Names(fromJustfile(dir)); !slices.Equal(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
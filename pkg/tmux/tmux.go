@@ -0,0 +1,127 @@
+package tmux
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"mpm/pkg/config"
+)
+
+// Available reports whether tmux is installed
+func Available() bool {
+	_, err := exec.LookPath("tmux")
+	return err == nil
+}
+
+// SessionName returns the tmux session name for a project. tmux does not
+// allow '.' or ':' in session names, so they are replaced, and a short hash
+// of the project name keeps e.g. "api.v2" and "api_v2" in separate sessions.
+func SessionName(project string) string {
+	name := strings.NewReplacer(".", "_", ":", "_").Replace(project)
+	if name == project {
+		return name
+	}
+
+	sum := sha1.Sum([]byte(project))
+	return name + "-" + hex.EncodeToString(sum[:3])
+}
+
+// Sessions returns the names of the running tmux sessions. Without tmux or
+// a running server there are none.
+func Sessions() map[string]bool {
+	sessions := make(map[string]bool)
+
+	output, err := exec.Command("tmux", "list-sessions", "-F", "#{session_name}").Output()
+	if err != nil {
+		return sessions
+	}
+
+	for _, name := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		if name != "" {
+			sessions[name] = true
+		}
+	}
+	return sessions
+}
+
+// HasSession reports whether the project's session is running
+func HasSession(project config.Project) bool {
+	return Sessions()[SessionName(project.Name)]
+}
+
+// Ensure creates the project's session in the background unless it is
+// already running. The session starts in the project directory with the
+// project's window layout, or a single shell without one. It reports
+// whether a session was created.
+func Ensure(project config.Project) (bool, error) {
+	if !Available() {
+		return false, fmt.Errorf("tmux is not installed")
+	}
+	if HasSession(project) {
+		return false, nil
+	}
+
+	name := SessionName(project.Name)
+	windows := project.TmuxWindows
+	if len(windows) == 0 {
+		windows = []config.TmuxWindow{{}}
+	}
+
+	var first string
+	for i, w := range windows {
+		args := []string{"new-window", "-d", "-t", "=" + name + ":"}
+		if i == 0 {
+			args = []string{"new-session", "-d", "-s", name}
+		}
+		args = append(args, "-P", "-F", "#{window_id}", "-c", project.Path)
+		if w.Name != "" {
+			args = append(args, "-n", w.Name)
+		}
+
+		output, err := run(args...)
+		if err != nil {
+			return false, err
+		}
+		id := strings.TrimSpace(output)
+		if i == 0 {
+			first = id
+		}
+
+		// Type the command into the window's shell so the window stays
+		// open after the command exits
+		if w.Command != "" {
+			if _, err := run("send-keys", "-t", id, w.Command, "Enter"); err != nil {
+				return false, err
+			}
+		}
+	}
+
+	if _, err := run("select-window", "-t", first); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// AttachCmd returns the command that brings the project's session to the
+// terminal: attaching to it, or switching the client when already inside
+// tmux
+func AttachCmd(project config.Project) *exec.Cmd {
+	target := "=" + SessionName(project.Name)
+	if os.Getenv("TMUX") != "" {
+		return exec.Command("tmux", "switch-client", "-t", target)
+	}
+	return exec.Command("tmux", "attach-session", "-t", target)
+}
+
+// run runs a tmux command and returns its output
+func run(args ...string) (string, error) {
+	output, err := exec.Command("tmux", args...).CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("tmux %s: %s", args[0], strings.TrimSpace(string(output)))
+	}
+	return string(output), nil
+}
@@ -0,0 +1,29 @@
+package tmux
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestSessionName(t *testing.T) {
+	// Names tmux accepts are kept as they are
+	for _, name := range []string{"api", "api_v2", "my-project", "api@fix"} {
+		if got := SessionName(name); got != name {
+			t.Errorf("SessionName(%q) = %q, want it unchanged", name, got)
+		}
+	}
+
+	// Rewritten names stay valid and apart from each other
+	valid := regexp.MustCompile(`^api_v2-[0-9a-f]{6}$`)
+	seen := map[string]string{"api_v2": "api_v2"}
+	for _, name := range []string{"api.v2", "api:v2"} {
+		got := SessionName(name)
+		if !valid.MatchString(got) {
+			t.Errorf("SessionName(%q) = %q", name, got)
+		}
+		if other, ok := seen[got]; ok {
+			t.Errorf("%q and %q share the session %q", name, other, got)
+		}
+		seen[got] = name
+	}
+}
@@ -0,0 +1,143 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// ChecklistModel is an inline multi-select list where every item starts
+// checked and the user unticks what they do not want
+type ChecklistModel struct {
+	Prompt    string
+	Items     []PickerItem
+	Checked   []bool
+	Cursor    int
+	Confirmed bool
+	Done      bool
+}
+
+// Init initializes the checklist
+func (m ChecklistModel) Init() tea.Cmd {
+	return nil
+}
+
+// Update handles keyboard navigation and toggling in the checklist
+func (m ChecklistModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	switch keyMsg.String() {
+	case "up", "k":
+		if m.Cursor > 0 {
+			m.Cursor--
+		}
+	case "down", "j":
+		if m.Cursor < len(m.Items)-1 {
+			m.Cursor++
+		}
+	case " ", "x":
+		if len(m.Checked) > 0 {
+			m.Checked[m.Cursor] = !m.Checked[m.Cursor]
+		}
+	case "a":
+		// Toggle all: untick everything if all are ticked, otherwise tick all
+		all := true
+		for _, c := range m.Checked {
+			all = all && c
+		}
+		for i := range m.Checked {
+			m.Checked[i] = !all
+		}
+	case "enter":
+		m.Confirmed = true
+		m.Done = true
+		return m, tea.Quit
+	case "esc", "q", "ctrl+c":
+		m.Done = true
+		return m, tea.Quit
+	}
+
+	return m, nil
+}
+
+// View renders the visible window of the checklist
+func (m ChecklistModel) View() string {
+	if m.Done {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString(TitleStyle.Render(m.Prompt) + "\n")
+
+	// Keep the cursor inside the visible window
+	start := 0
+	if m.Cursor >= pickerHeight {
+		start = m.Cursor - pickerHeight + 1
+	}
+	end := start + pickerHeight
+	if end > len(m.Items) {
+		end = len(m.Items)
+	}
+
+	for i := start; i < end; i++ {
+		box := "[ ]"
+		if m.Checked[i] {
+			box = "[x]"
+		}
+
+		item := m.Items[i]
+		line := fmt.Sprintf("%s %s %s", box, item.Title, PathStyle.Render(item.Detail))
+		if i == m.Cursor {
+			b.WriteString(SelectedItemStyle.Render("> "+line) + "\n")
+		} else {
+			b.WriteString(ItemStyle.Render(line) + "\n")
+		}
+	}
+
+	selected := 0
+	for _, c := range m.Checked {
+		if c {
+			selected++
+		}
+	}
+	b.WriteString(HelpStyle.Render(fmt.Sprintf("  %d of %d selected • space to toggle • a to toggle all • enter to confirm • esc to cancel",
+		selected, len(m.Items))))
+
+	return b.String()
+}
+
+// RunChecklist shows an inline checklist and returns the indexes of the
+// ticked items, or nil if the user cancelled
+func RunChecklist(prompt string, items []PickerItem) ([]int, error) {
+	checked := make([]bool, len(items))
+	for i := range checked {
+		checked[i] = true
+	}
+
+	model := ChecklistModel{Prompt: " " + prompt + " ", Items: items, Checked: checked}
+
+	useStderr()
+	p := tea.NewProgram(model, tea.WithOutput(os.Stderr), tea.WithInputTTY())
+	result, err := p.Run()
+	if err != nil {
+		return nil, fmt.Errorf("running checklist: %w", err)
+	}
+
+	m, ok := result.(ChecklistModel)
+	if !ok || !m.Confirmed {
+		return nil, nil
+	}
+
+	var selected []int
+	for i, c := range m.Checked {
+		if c {
+			selected = append(selected, i)
+		}
+	}
+	return selected, nil
+}
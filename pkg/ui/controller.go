@@ -3,10 +3,6 @@ package ui
 import (
 	"fmt"
 	"os"
-	"os/exec"
-	"path/filepath"
-	"runtime"
-	"sort"
 	"strings"
 
 	"github.com/charmbracelet/bubbles/key"
@@ -17,6 +13,10 @@ import (
 	"mpm/pkg/config"
 	"mpm/pkg/fs"
 	"mpm/pkg/health"
+	"mpm/pkg/launch"
+	"mpm/pkg/shell"
+	"mpm/pkg/task"
+	"mpm/pkg/tmux"
 )
 
 // Custom message type to hold command output
@@ -33,6 +33,9 @@ func (m ListModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		// Any key press dismisses the last error
+		m.ErrorMessage = ""
+
 		if m.ShowForm {
 			return handleFormView(m, msg)
 		} else if m.ShowActions {
@@ -41,6 +44,23 @@ func (m ListModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return handleListView(m, msg)
 		}
 
+	case launchFinishedMsg:
+		if msg.err != nil {
+			m.ErrorMessage = msg.err.Error()
+			return m, nil
+		}
+		m.recordOpen()
+		m.Quitting = true
+		return m, tea.Quit
+
+	case taskFinishedMsg:
+		// Quit either way so the output can be read, and let mpm exit with
+		// the task's code
+		m.TaskErr = msg.err
+		m.recordOpen()
+		m.Quitting = true
+		return m, tea.Quit
+
 	case tea.WindowSizeMsg:
 		// Store window dimensions in the model
 		m.WindowWidth = msg.Width
@@ -67,6 +87,7 @@ func handleFormView(m ListModel, msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
 	case "esc":
 		m.ShowForm = false
+		m.EditingName = ""
 		return m, nil
 
 	case "tab", "shift+tab":
@@ -90,49 +111,26 @@ func handleFormView(m ListModel, msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case "enter":
 		if m.FormFocused == len(m.FormInputs)-1 {
 			// Submit form on Enter when on last field
-			name := strings.TrimSpace(m.FormInputs[0].Value())
-			path := strings.TrimSpace(m.FormInputs[1].Value())
-			category := strings.TrimSpace(m.FormInputs[2].Value())
+			project := m.formProject()
 
-			if name != "" && path != "" {
-				config.AddProject(name, path, category)
-
-				// Reload projects for the list
-				refreshedConfig := config.LoadConfig()
-				m.ProjectItems = []list.Item{}
-
-				for _, p := range refreshedConfig.Projects {
-					cat := p.Category
-					if cat == "" {
-						cat = "Uncategorized"
-					}
-					m.ProjectItems = append(m.ProjectItems, ProjectItem{Name: p.Name, Path: p.Path, Category: cat})
+			if project.Name != "" && project.Path != "" {
+				if err := m.submitForm(project); err != nil {
+					m.ErrorMessage = err.Error()
+					return m, nil
 				}
 
-				// Apply current sort order to the updated project list
-				if m.SortOrder == "asc" {
-					sort.Slice(m.ProjectItems, func(i, j int) bool {
-						return m.ProjectItems[i].(ProjectItem).Name < m.ProjectItems[j].(ProjectItem).Name
-					})
-				} else {
-					sort.Slice(m.ProjectItems, func(i, j int) bool {
-						return m.ProjectItems[i].(ProjectItem).Name > m.ProjectItems[j].(ProjectItem).Name
-					})
+				// Reload projects for the list
+				if err := m.reloadProjects(); err != nil {
+					m.ErrorMessage = err.Error()
 				}
 
-				m.List.SetItems(m.ProjectItems)
-
-				// Reset form
-				for i := range m.FormInputs {
-					m.FormInputs[i].SetValue("")
-				}
+				// Reset form and leave the action view of an edited project,
+				// which would show stale details
+				m.FormInputs = InitForm()
 				m.FormFocused = 0
-				m.FormInputs[0].Focus()
-				for i := 1; i < len(m.FormInputs); i++ {
-					m.FormInputs[i].Blur()
-				}
-
 				m.ShowForm = false
+				m.ShowActions = false
+				m.EditingName = ""
 			}
 
 			return m, nil
@@ -155,9 +153,33 @@ func handleFormView(m ListModel, msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	return m, cmd
 }
 
+// submitForm adds the project from the form, or applies it to the project
+// being edited
+func (m ListModel) submitForm(project config.Project) error {
+	if m.EditingName == "" {
+		_, err := m.Store.AddProject(project)
+		return err
+	}
+
+	absPath, err := config.ExpandPath(project.Path)
+	if err != nil {
+		return err
+	}
+
+	return m.Store.UpdateProject(m.EditingName, func(p *config.Project) error {
+		p.Name = project.Name
+		p.Path = absPath
+		p.Category = project.Category
+		p.Tags = project.Tags
+		p.Aliases = project.Aliases
+		p.Description = project.Description
+		return nil
+	})
+}
+
 // handleActionsView handles keyboard events in the action view
 func handleActionsView(m ListModel, msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	actionKeys := NewActionKeyMap()
+	actionKeys := NewActionKeyMap(m.Launchers, m.Tasks)
 
 	switch {
 	case key.Matches(msg, actionKeys.Back):
@@ -220,132 +242,126 @@ func handleActionsView(m ListModel, msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 	case key.Matches(msg, actionKeys.GoTo):
 		if m.SelectedItem != nil {
-			// Instead of just returning a cd command, directly change the directory
-			// This requires different approaches depending on the OS
-			if runtime.GOOS == "darwin" || runtime.GOOS == "linux" {
-				// For macOS and Linux, we can use a special technique:
-				// Write the cd command to a temporary file that the shell can source
-				cdFile := filepath.Join(os.TempDir(), "mpm_cd_command")
-				cdCommand := fmt.Sprintf("cd %s", m.SelectedItem.Path)
-				err := os.WriteFile(cdFile, []byte(cdCommand), 0644)
-				if err == nil {
-					// Inform the user what to do
-					fmt.Printf("\nTo change to the selected directory after exiting:\nsource %s\n", cdFile)
-				}
-			} else {
-				// For other platforms, we'll still use the return value approach
-				m.QuitCommand = fmt.Sprintf("cd %s", m.SelectedItem.Path)
-			}
-			m.Quitting = true
-			return m, tea.Quit
-		}
-
-	case key.Matches(msg, actionKeys.VSCode):
-		if m.SelectedItem != nil {
-			cmd := exec.Command("code", m.SelectedItem.Path)
-			cmd.Start()
+			// The shell wrapper from `mpm init` evaluates this after exiting
+			m.QuitCommand = shell.CdCommand(m.SelectedItem.Path, "")
+			m.recordOpen()
 			m.Quitting = true
 			return m, tea.Quit
 		}
 
-	case key.Matches(msg, actionKeys.Zed):
-		if m.SelectedItem != nil {
-			cmd := exec.Command("zed", m.SelectedItem.Path)
-			cmd.Start()
-			m.Quitting = true
-			return m, tea.Quit
+	case key.Matches(msg, actionKeys.Open):
+		if m.PreferredErr != "" {
+			m.ErrorMessage = m.PreferredErr
+			return m, nil
 		}
+		return m.launch(m.Preferred)
 
-	case key.Matches(msg, actionKeys.Cursor):
+	case key.Matches(msg, actionKeys.Tmux):
 		if m.SelectedItem != nil {
-			cmd := exec.Command("cursor", m.SelectedItem.Path)
-			cmd.Start()
-			m.Quitting = true
-			return m, tea.Quit
+			project := m.SelectedItem.Project
+			if _, err := tmux.Ensure(project); err != nil {
+				m.ErrorMessage = err.Error()
+				return m, nil
+			}
+			// tmux takes over the terminal until the client detaches
+			return m, tea.ExecProcess(tmux.AttachCmd(project), func(err error) tea.Msg {
+				return launchFinishedMsg{err: err}
+			})
 		}
 
-	case key.Matches(msg, actionKeys.Neovim):
+	case key.Matches(msg, actionKeys.Edit):
 		if m.SelectedItem != nil {
-			// Return command to navigate and open nvim
-			m.Quitting = true
-			m.QuitCommand = fmt.Sprintf("cd %s && nvim .", m.SelectedItem.Path)
-			return m, tea.Quit
+			m.openForm(m.SelectedItem)
 		}
+		return m, nil
 
-	case key.Matches(msg, actionKeys.Finder):
+	case key.Matches(msg, actionKeys.Delete):
 		if m.SelectedItem != nil {
-			var cmd *exec.Cmd
-			switch runtime.GOOS {
-			case "darwin":
-				cmd = exec.Command("open", m.SelectedItem.Path)
-			case "windows":
-				cmd = exec.Command("explorer", m.SelectedItem.Path)
-			default: // Linux
-				cmd = exec.Command("xdg-open", m.SelectedItem.Path)
+			if err := m.Store.RemoveProject(m.SelectedItem.Name); err != nil {
+				m.ErrorMessage = err.Error()
 			}
-			cmd.Start()
-			m.Quitting = true
-			return m, tea.Quit
-		}
 
-	case key.Matches(msg, actionKeys.Sublime):
-		if m.SelectedItem != nil {
-			cmd := exec.Command("subl", m.SelectedItem.Path)
-			cmd.Start()
-			m.Quitting = true
-			return m, tea.Quit
+			// Reload projects
+			if err := m.reloadProjects(); err != nil {
+				m.ErrorMessage = err.Error()
+			}
+			m.ShowActions = false
 		}
+		return m, nil
+	}
 
-	case key.Matches(msg, actionKeys.Trae):
-		if m.SelectedItem != nil {
-			cmd := exec.Command("trae", m.SelectedItem.Path)
-			cmd.Start()
-			m.Quitting = true
-			return m, tea.Quit
+	for i, binding := range actionKeys.Launchers {
+		if key.Matches(msg, binding) {
+			return m.launch(m.Launchers[i])
 		}
-
-	case key.Matches(msg, actionKeys.TextMate):
-		if m.SelectedItem != nil {
-			cmd := exec.Command("mate", m.SelectedItem.Path)
-			cmd.Start()
-			m.Quitting = true
-			return m, tea.Quit
+	}
+	for i, binding := range actionKeys.Tasks {
+		if key.Matches(msg, binding) && m.SelectedItem != nil {
+			// The task's output stays on the terminal after mpm quits
+			cmd := m.Tasks[i].Cmd(m.SelectedItem.Path, nil)
+			return m, tea.ExecProcess(cmd, func(err error) tea.Msg {
+				return taskFinishedMsg{err: err}
+			})
 		}
+	}
 
-	case key.Matches(msg, actionKeys.Delete):
-		if m.SelectedItem != nil {
-			config.RemoveProject(m.SelectedItem.Name)
+	return m, nil
+}
 
-			// Reload projects
-			refreshedConfig := config.LoadConfig()
-			m.ProjectItems = []list.Item{}
+// launchFinishedMsg reports the end of a foreground launcher
+type launchFinishedMsg struct {
+	err error
+}
 
-			for _, p := range refreshedConfig.Projects {
-				cat := p.Category
-				if cat == "" {
-					cat = "Uncategorized"
-				}
-				m.ProjectItems = append(m.ProjectItems, ProjectItem{Name: p.Name, Path: p.Path, Category: cat})
-			}
+// taskFinishedMsg reports the end of a task run from the action view
+type taskFinishedMsg struct {
+	err error
+}
 
-			// Apply current sort order
-			if m.SortOrder == "asc" {
-				sort.Slice(m.ProjectItems, func(i, j int) bool {
-					return m.ProjectItems[i].(ProjectItem).Name < m.ProjectItems[j].(ProjectItem).Name
-				})
-			} else {
-				sort.Slice(m.ProjectItems, func(i, j int) bool {
-					return m.ProjectItems[i].(ProjectItem).Name > m.ProjectItems[j].(ProjectItem).Name
-				})
-			}
+// launch opens the selected project with a launcher and quits, or stays in
+// the action view and shows why the launcher failed. Foreground programs
+// take over the terminal until they exit.
+func (m ListModel) launch(l launch.Launcher) (tea.Model, tea.Cmd) {
+	if m.SelectedItem == nil {
+		return m, nil
+	}
+	project := m.SelectedItem.Project
 
-			m.List.SetItems(m.ProjectItems)
-			m.ShowActions = false
+	switch l.Mode {
+	case launch.Shell:
+		command, err := l.ShellCommand(project)
+		if err != nil {
+			m.ErrorMessage = err.Error()
+			return m, nil
+		}
+		m.QuitCommand = command
+	case launch.Foreground:
+		cmd, err := l.Cmd(project)
+		if err != nil {
+			m.ErrorMessage = err.Error()
+			return m, nil
+		}
+		return m, tea.ExecProcess(cmd, func(err error) tea.Msg {
+			return launchFinishedMsg{err: err}
+		})
+	default:
+		if err := l.Run(project); err != nil {
+			m.ErrorMessage = err.Error()
+			return m, nil
 		}
-		return m, nil
 	}
 
-	return m, nil
+	m.recordOpen()
+	m.Quitting = true
+	return m, tea.Quit
+}
+
+// recordOpen records that the selected project was opened. Failing to
+// update the ranking data is not worth blocking the user over.
+func (m ListModel) recordOpen() {
+	if m.SelectedItem != nil {
+		m.Store.RecordOpen(m.SelectedItem.Name)
+	}
 }
 
 // handleListView handles keyboard events in the list view
@@ -368,20 +384,9 @@ func handleListView(m ListModel, msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		case key.Matches(msg, m.Keys.Sort):
 			// Only sort in projects view
 			if m.ViewMode == "projects" {
-				// Toggle sort order between asc and desc
-				if m.SortOrder == "asc" {
-					m.SortOrder = "desc"
-					// Sort projects in descending order
-					sort.Slice(m.ProjectItems, func(i, j int) bool {
-						return m.ProjectItems[i].(ProjectItem).Name > m.ProjectItems[j].(ProjectItem).Name
-					})
-				} else {
-					m.SortOrder = "asc"
-					// Sort projects in ascending order
-					sort.Slice(m.ProjectItems, func(i, j int) bool {
-						return m.ProjectItems[i].(ProjectItem).Name < m.ProjectItems[j].(ProjectItem).Name
-					})
-				}
+				// Cycle sort order between asc, desc and most used
+				m.SortOrder = nextSortOrder(m.SortOrder)
+				sortProjectItems(m.ProjectItems, m.SortOrder)
 
 				// Apply sorting to the current view
 				if m.SelectedCategory != "" {
@@ -450,15 +455,7 @@ func handleListView(m ListModel, msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 						}
 
 						// Apply current sort order to filtered projects
-						if m.SortOrder == "asc" {
-							sort.Slice(filteredProjects, func(i, j int) bool {
-								return filteredProjects[i].(ProjectItem).Name < filteredProjects[j].(ProjectItem).Name
-							})
-						} else {
-							sort.Slice(filteredProjects, func(i, j int) bool {
-								return filteredProjects[i].(ProjectItem).Name > filteredProjects[j].(ProjectItem).Name
-							})
-						}
+						sortProjectItems(filteredProjects, m.SortOrder)
 
 						// Switch to projects view with filtered projects
 						m.ViewMode = "projects"
@@ -473,6 +470,22 @@ func handleListView(m ListModel, msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 						m.ShowActions = true
 						m.ScrollOffset = 0 // Reset scroll position when entering details view
 
+						// Pressing Enter again opens the preferred launcher
+						m.Preferred, m.PreferredErr = launch.Launcher{}, ""
+						if preferred, err := launch.Preferred(m.Launchers, m.CategoryLaunchers, selected.Project); err != nil {
+							m.PreferredErr = err.Error()
+						} else {
+							m.Preferred = preferred
+						}
+
+						// Tasks can be run with the number keys
+						m.Tasks, m.TasksErr = nil, ""
+						if tasks, err := task.Discover(selected.Project); err != nil {
+							m.TasksErr = err.Error()
+						} else {
+							m.Tasks = tasks
+						}
+
 						// Scan the project directory for file chart and health status
 						projectPath := m.SelectedItem.Path
 						if _, err := os.Stat(projectPath); err == nil {
@@ -482,11 +495,23 @@ func handleListView(m ListModel, msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 							m.FileTypeCounts = fs.CountFileTypes(m.FileChart)
 							// Check Git status
 							m.GitInfo = fs.CheckGitStatus(projectPath)
+							// Link the other worktrees of the repository to their projects
+							m.WorktreeNames = nil
+							if len(m.GitInfo.Worktrees) > 1 {
+								m.WorktreeNames = m.projectNames()
+							}
 							// Perform project health scan just once and cache the result
 							if !m.HealthScanned {
 								m.HealthStatus = health.ScanProjectHealth(projectPath)
 								m.HealthScanned = true
 							}
+						} else {
+							// Don't show details left over from a previous project
+							m.FileChart = []fs.FileEntry{}
+							m.FileTypeCounts = []fs.FileTypeCount{}
+							m.GitInfo = fs.GitInfo{}
+							m.HealthStatus = health.HealthStatus{}
+							m.HealthScanned = true
 						}
 					}
 				}
@@ -496,15 +521,16 @@ func handleListView(m ListModel, msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		case key.Matches(msg, m.Keys.Add):
 			// Only allow adding projects in projects view
 			if m.ViewMode == "projects" {
-				m.ShowForm = true
-				for i := range m.FormInputs {
-					if i == 0 {
-						m.FormInputs[i].Focus()
-					} else {
-						m.FormInputs[i].Blur()
-					}
+				m.openForm(nil)
+			}
+			return m, nil
+
+		case key.Matches(msg, m.Keys.Edit):
+			// Only allow editing projects in projects view
+			if m.ViewMode == "projects" && len(m.List.Items()) > 0 {
+				if selected, ok := m.List.SelectedItem().(ProjectItem); ok {
+					m.openForm(&selected)
 				}
-				m.FormFocused = 0
 			}
 			return m, nil
 
@@ -513,50 +539,14 @@ func handleListView(m ListModel, msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			if m.ViewMode == "projects" && len(m.List.Items()) > 0 {
 				selected, ok := m.List.SelectedItem().(ProjectItem)
 				if ok {
-					config.RemoveProject(selected.Name)
-
-					// Reload projects and rebuild category items
-					refreshedConfig := config.LoadConfig()
-					projectItems := []list.Item{}
-					categoryMap := make(map[string]int)
-
-					for _, p := range refreshedConfig.Projects {
-						cat := p.Category
-						if cat == "" {
-							cat = "Uncategorized"
-						}
-						projectItems = append(projectItems, ProjectItem{Name: p.Name, Path: p.Path, Category: cat})
-
-						// Count projects per category
-						categoryMap[cat]++
-					}
-
-					// Apply current sort order
-					if m.SortOrder == "asc" {
-						sort.Slice(projectItems, func(i, j int) bool {
-							return projectItems[i].(ProjectItem).Name < projectItems[j].(ProjectItem).Name
-						})
-					} else {
-						sort.Slice(projectItems, func(i, j int) bool {
-							return projectItems[i].(ProjectItem).Name > projectItems[j].(ProjectItem).Name
-						})
+					if err := m.Store.RemoveProject(selected.Name); err != nil {
+						m.ErrorMessage = err.Error()
 					}
 
-					// Create category items
-					categoryItems := []list.Item{}
-					for cat, count := range categoryMap {
-						categoryItems = append(categoryItems, CategoryItem{Name: cat, Count: count})
+					// Reload projects and rebuild category items
+					if err := m.reloadProjects(); err != nil {
+						m.ErrorMessage = err.Error()
 					}
-
-					// Sort categories alphabetically
-					sort.Slice(categoryItems, func(i, j int) bool {
-						return categoryItems[i].(CategoryItem).Name < categoryItems[j].(CategoryItem).Name
-					})
-
-					// Update model with new items
-					m.ProjectItems = projectItems
-					m.CategoryItems = categoryItems
-					m.List.SetItems(projectItems)
 				}
 			}
 			return m, nil
@@ -680,21 +670,28 @@ func (m ListModel) View() string {
 }
 
 // RunInteractive starts the interactive mode of the application
-func RunInteractive() string {
-	p := tea.NewProgram(InitialModel(), tea.WithAltScreen())
+func RunInteractive(store *config.Store) (string, error) {
+	initial, err := InitialModel(store)
+	if err != nil {
+		return "", err
+	}
+
+	// Draw on stderr so the shell wrapper can capture the command printed
+	// on stdout
+	useStderr()
+	p := tea.NewProgram(initial, tea.WithAltScreen(), tea.WithOutput(os.Stderr), tea.WithInputTTY())
 
 	// Run the program
 	model, err := p.Run()
 	if err != nil {
-		fmt.Printf("Error running interactive mode: %v\n", err)
-		os.Exit(1)
+		return "", fmt.Errorf("running interactive mode: %w", err)
 	}
 
 	// Check if we have a command to execute (like cd or nvim)
 	if m, ok := model.(ListModel); ok && m.Quitting {
 		// Return the command stored in the model
-		return m.QuitCommand
+		return m.QuitCommand, m.TaskErr
 	}
 
-	return ""
+	return "", nil
 }
@@ -48,7 +48,8 @@ func formatCount(count int, zeroIsOk bool) string {
 
 // RenderHealthDashboard returns a formatted health dashboard view
 func (m ListModel) RenderHealthDashboard() string {
-	if m.SelectedItem == nil {
+	// A missing directory has no health to show
+	if m.SelectedItem == nil || m.SelectedItem.Missing {
 		return ""
 	}
 
@@ -58,23 +59,9 @@ func (m ListModel) RenderHealthDashboard() string {
 		healthStatus = health.ScanProjectHealth(m.SelectedItem.Path)
 	}
 
-	// Create styled health indicators
-	depIndicator := IndicatorStyle.Copy().Foreground(HealthyColor).Render("⬤")
-	if healthStatus.DependencyStatus.OutdatedDeps > 0 {
-		depIndicator = IndicatorStyle.Copy().Foreground(WarningColor).Render("⬤")
-	}
-	if healthStatus.DependencyStatus.Vulnerabilities > 0 {
-		depIndicator = IndicatorStyle.Copy().Foreground(CriticalColor).Render("⬤")
-	}
-
-	ciIndicator := IndicatorStyle.Copy().Foreground(CriticalColor).Render("⬤")
-	if healthStatus.CIStatus.HasCI {
-		if healthStatus.CIStatus.LastBuildStatus == "Success" && healthStatus.CIStatus.LastTestStatus == "Success" {
-			ciIndicator = IndicatorStyle.Copy().Foreground(HealthyColor).Render("⬤")
-		} else if healthStatus.CIStatus.LastBuildStatus == "Success" || healthStatus.CIStatus.LastTestStatus == "Success" {
-			ciIndicator = IndicatorStyle.Copy().Foreground(WarningColor).Render("⬤")
-		}
-	}
+	// Create styled health indicators, with the levels `mpm status` reports
+	depIndicator := health.LevelStyle(healthStatus.DependencyStatus.Level()).Inherit(IndicatorStyle).Render("⬤")
+	ciIndicator := health.LevelStyle(healthStatus.CIStatus.Level()).Inherit(IndicatorStyle).Render("⬤")
 
 	gitIndicator := IndicatorStyle.Copy().Foreground(CriticalColor).Render("⬤")
 	if m.GitInfo.HasGit {
@@ -3,7 +3,9 @@ package ui
 import (
 	"fmt"
 	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
@@ -13,6 +15,9 @@ import (
 	"mpm/pkg/config"
 	"mpm/pkg/fs"
 	"mpm/pkg/health"
+	"mpm/pkg/launch"
+	"mpm/pkg/task"
+	"mpm/pkg/tmux"
 )
 
 // ProjectItem represents a project in the UI list
@@ -20,18 +25,75 @@ type ProjectItem struct {
 	Name     string
 	Path     string
 	Category string
+	Tags     []string
+	Aliases  []string
+	Desc     string         // Free-text project description
+	Score    float64        // Frecency score used by the "most used" sort
+	Missing  bool           // Whether the project directory no longer exists
+	Tmux     bool           // Whether the project's tmux session is running
+	Nested   bool           // Shown below its parent project, as one of its worktrees
+	Project  config.Project // The project as stored in the config
+}
+
+// newProjectItem converts a configured project into a list item
+func newProjectItem(p config.Project) ProjectItem {
+	cat := p.Category
+	if cat == "" {
+		cat = "Uncategorized"
+	}
+
+	return ProjectItem{
+		Name:     p.Name,
+		Path:     p.Path,
+		Category: cat,
+		Tags:     p.Tags,
+		Aliases:  p.Aliases,
+		Desc:     p.Description,
+		Score:    p.Frecency(time.Now()),
+		Missing:  !fs.PathExists(p.Path),
+		Project:  p,
+	}
 }
 
 // Title implements list.Item interface
-func (i ProjectItem) Title() string { return i.Name }
+func (i ProjectItem) Title() string {
+	title := i.Name
+	if i.Missing {
+		title = ErrorStyle.Render("⚠ " + i.Name)
+	}
+	if i.Nested {
+		title = PathStyle.Render("└ ") + title
+	}
+	if len(i.Aliases) > 0 {
+		title += " " + PathStyle.Render("("+strings.Join(i.Aliases, ", ")+")")
+	}
+	if i.Tmux {
+		title += " " + TagStyle.Render("[tmux]")
+	}
+	return title
+}
 
 // Description implements list.Item interface
 func (i ProjectItem) Description() string {
-	return fmt.Sprintf("%s %s", CategoryStyle.Render("["+i.Category+"]"), PathStyle.Render(i.Path))
+	parts := []string{CategoryStyle.Render("[" + i.Category + "]")}
+	for _, t := range i.Tags {
+		parts = append(parts, TagStyle.Render("#"+t))
+	}
+	if i.Missing {
+		parts = append(parts, ErrorStyle.Render(i.Path+" (missing)"))
+	} else {
+		parts = append(parts, PathStyle.Render(i.Path))
+	}
+	return strings.Join(parts, " ")
 }
 
 // FilterValue implements list.Item interface
-func (i ProjectItem) FilterValue() string { return i.Name + " " + i.Category + " " + i.Path }
+func (i ProjectItem) FilterValue() string {
+	fields := []string{i.Name, i.Category, i.Path, i.Desc}
+	fields = append(fields, i.Tags...)
+	fields = append(fields, i.Aliases...)
+	return strings.Join(fields, " ")
+}
 
 // CategoryItem represents a category in the category view
 type CategoryItem struct {
@@ -52,28 +114,40 @@ func (i CategoryItem) FilterValue() string { return i.Name }
 
 // ListModel is the main model for the list view
 type ListModel struct {
-	List             list.Model
-	Keys             ListKeyMap
-	SelectedItem     *ProjectItem
-	ShowActions      bool
-	ShowForm         bool
-	FormInputs       []textinput.Model
-	FormFocused      int
-	Quitting         bool
-	FileChart        []fs.FileEntry
-	FileTypeCounts   []fs.FileTypeCount
-	GitInfo          fs.GitInfo
-	QuitCommand      string              // To store the command to execute after quitting
-	ViewMode         string              // "projects" or "categories"
-	SelectedCategory string              // Currently selected category in category view
-	CategoryItems    []list.Item         // Store category items for category view
-	ProjectItems     []list.Item         // Store project items for project view
-	SortOrder        string              // "asc" or "desc" for project sorting
-	HealthScanned    bool                // Whether health scan has been performed
-	HealthStatus     health.HealthStatus // Project health scan results
-	ScrollOffset     int                 // Scroll position for detailed views
-	WindowWidth      int                 // Terminal window width
-	WindowHeight     int                 // Terminal window height
+	Store             *config.Store
+	List              list.Model
+	Keys              ListKeyMap
+	SelectedItem      *ProjectItem
+	ShowActions       bool
+	ShowForm          bool
+	EditingName       string // Project being edited in the form, empty when adding
+	FormInputs        []textinput.Model
+	FormFocused       int
+	Quitting          bool
+	FileChart         []fs.FileEntry
+	FileTypeCounts    []fs.FileTypeCount
+	GitInfo           fs.GitInfo
+	WorktreeNames     map[string]string   // Registered projects by canonical path, to name the selected repo's worktrees
+	QuitCommand       string              // To store the command to execute after quitting
+	ViewMode          string              // "projects" or "categories"
+	SelectedCategory  string              // Currently selected category in category view
+	CategoryItems     []list.Item         // Store category items for category view
+	ProjectItems      []list.Item         // Store project items for project view
+	SortOrder         string              // "asc", "desc" or "used" for project sorting
+	HealthScanned     bool                // Whether health scan has been performed
+	HealthStatus      health.HealthStatus // Project health scan results
+	ScrollOffset      int                 // Scroll position for detailed views
+	WindowWidth       int                 // Terminal window width
+	WindowHeight      int                 // Terminal window height
+	ErrorMessage      string              // Last error to show in the footer
+	Launchers         []launch.Launcher   // Ways to open a project from the action view
+	Installed         map[string]bool     // Launchers whose program is on PATH, by name
+	CategoryLaunchers map[string]string   // Preferred launcher by category
+	Preferred         launch.Launcher     // Preferred launcher of the selected project
+	PreferredErr      string              // Why the preferred launcher could not be determined
+	Tasks             []task.Task         // Tasks of the selected project
+	TasksErr          string              // Why the tasks could not be read
+	TaskErr           error               // Failure of the task run before quitting
 }
 
 // ListKeyMap defines key bindings for the list view
@@ -87,61 +161,62 @@ type ListKeyMap struct {
 	Quit       key.Binding
 	ToggleView key.Binding
 	Sort       key.Binding
+	Edit       key.Binding
 }
 
 // ActionKeyMap defines key bindings for the action view
 type ActionKeyMap struct {
-	GoTo     key.Binding
-	VSCode   key.Binding
-	Zed      key.Binding
-	Cursor   key.Binding
-	Neovim   key.Binding
-	Finder   key.Binding
-	Sublime  key.Binding
-	Trae     key.Binding
-	TextMate key.Binding
-	Delete   key.Binding
-	Back     key.Binding
+	GoTo      key.Binding
+	Open      key.Binding   // Opens the project's preferred launcher
+	Launchers []key.Binding // One binding per launcher, in the same order
+	Tmux      key.Binding
+	Tasks     []key.Binding // Keys 1-9 for the first nine tasks
+	Edit      key.Binding
+	Delete    key.Binding
+	Back      key.Binding
 }
 
-// NewActionKeyMap creates a new action key map with default bindings
-func NewActionKeyMap() ActionKeyMap {
+// NewActionKeyMap creates the action key map, with a binding for every
+// launcher that has a key and for the first nine tasks
+func NewActionKeyMap(launchers []launch.Launcher, tasks []task.Task) ActionKeyMap {
+	bindings := make([]key.Binding, len(launchers))
+	for i, l := range launchers {
+		bindings[i] = key.NewBinding(
+			key.WithKeys(l.Key),
+			key.WithHelp(l.Key, "open in "+l.Label),
+		)
+		if l.Key == "" {
+			bindings[i].SetEnabled(false)
+		}
+	}
+
+	taskBindings := make([]key.Binding, min(len(tasks), 9))
+	for i := range taskBindings {
+		k := strconv.Itoa(i + 1)
+		taskBindings[i] = key.NewBinding(
+			key.WithKeys(k),
+			key.WithHelp(k, "run "+tasks[i].Name),
+		)
+	}
+
 	return ActionKeyMap{
 		GoTo: key.NewBinding(
-			key.WithKeys("g", "enter"),
-			key.WithHelp("g/enter", "go to directory"),
-		),
-		VSCode: key.NewBinding(
-			key.WithKeys("v"),
-			key.WithHelp("v", "open in VS Code"),
-		),
-		Zed: key.NewBinding(
-			key.WithKeys("z"),
-			key.WithHelp("z", "open in Zed"),
+			key.WithKeys("g"),
+			key.WithHelp("g", "go to directory"),
 		),
-		Cursor: key.NewBinding(
-			key.WithKeys("c"),
-			key.WithHelp("c", "open in Cursor"),
-		),
-		Neovim: key.NewBinding(
-			key.WithKeys("n"),
-			key.WithHelp("n", "open in Neovim"),
-		),
-		Finder: key.NewBinding(
-			key.WithKeys("f"),
-			key.WithHelp("f", "open in Finder/Explorer"),
-		),
-		Sublime: key.NewBinding(
-			key.WithKeys("s"),
-			key.WithHelp("s", "open in Sublime Text"),
+		Open: key.NewBinding(
+			key.WithKeys("enter"),
+			key.WithHelp("enter", "open in preferred editor"),
 		),
-		Trae: key.NewBinding(
-			key.WithKeys("t"),
-			key.WithHelp("t", "open in Trae"),
+		Launchers: bindings,
+		Tmux: key.NewBinding(
+			key.WithKeys("T"),
+			key.WithHelp("T", "open tmux session"),
 		),
-		TextMate: key.NewBinding(
-			key.WithKeys("m"),
-			key.WithHelp("m", "open in TextMate"),
+		Tasks: taskBindings,
+		Edit: key.NewBinding(
+			key.WithKeys("e"),
+			key.WithHelp("e", "edit project"),
 		),
 		Delete: key.NewBinding(
 			key.WithKeys("d"),
@@ -189,16 +264,31 @@ func NewListKeyMap() ListKeyMap {
 			key.WithKeys("tab"),
 			key.WithHelp("tab", "toggle view"),
 		),
+		Edit: key.NewBinding(
+			key.WithKeys("e"),
+			key.WithHelp("e", "edit project"),
+		),
 		Sort: key.NewBinding(
 			key.WithKeys("s"),
-			key.WithHelp("s", "toggle sort order"),
+			key.WithHelp("s", "cycle sort order"),
 		),
 	}
 }
 
-// InitForm initializes the form for adding new projects
+// Indexes of the fields in the project form
+const (
+	formName = iota
+	formPath
+	formCategory
+	formTags
+	formAliases
+	formDescription
+	formFieldCount
+)
+
+// InitForm initializes the form for adding or editing projects
 func InitForm() []textinput.Model {
-	inputs := make([]textinput.Model, 3)
+	inputs := make([]textinput.Model, formFieldCount)
 
 	for i := range inputs {
 		input := textinput.New()
@@ -206,13 +296,19 @@ func InitForm() []textinput.Model {
 		input.CursorStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#7D56F4"))
 
 		switch i {
-		case 0:
+		case formName:
 			input.Placeholder = "Project Name"
 			input.Focus()
-		case 1:
+		case formPath:
 			input.Placeholder = "Project Path (e.g., ~/projects/myapp)"
-		case 2:
+		case formCategory:
 			input.Placeholder = "Category (optional)"
+		case formTags:
+			input.Placeholder = "Tags, comma-separated (optional)"
+		case formAliases:
+			input.Placeholder = "Aliases, comma-separated (optional)"
+		case formDescription:
+			input.Placeholder = "Description (optional)"
 		}
 
 		inputs[i] = input
@@ -221,28 +317,62 @@ func InitForm() []textinput.Model {
 	return inputs
 }
 
-// InitialModel initializes the list model for the main view
-func InitialModel() ListModel {
-	// Load projects
-	config := config.LoadConfig()
+// openForm shows the project form, pre-filled from item when editing or
+// empty when item is nil
+func (m *ListModel) openForm(item *ProjectItem) {
+	m.FormInputs = InitForm()
+	m.FormFocused = formName
+	m.EditingName = ""
+
+	if item != nil {
+		category := item.Category
+		if category == "Uncategorized" {
+			category = ""
+		}
+
+		m.EditingName = item.Name
+		m.FormInputs[formName].SetValue(item.Name)
+		m.FormInputs[formPath].SetValue(item.Path)
+		m.FormInputs[formCategory].SetValue(category)
+		m.FormInputs[formTags].SetValue(strings.Join(item.Tags, ", "))
+		m.FormInputs[formAliases].SetValue(strings.Join(item.Aliases, ", "))
+		m.FormInputs[formDescription].SetValue(item.Desc)
+	}
+
+	m.ShowForm = true
+}
+
+// formProject builds a project from the current form values
+func (m ListModel) formProject() config.Project {
+	value := func(i int) string { return strings.TrimSpace(m.FormInputs[i].Value()) }
+
+	return config.Project{
+		Name:        value(formName),
+		Path:        value(formPath),
+		Category:    value(formCategory),
+		Tags:        config.NormalizeList(strings.Split(value(formTags), ",")),
+		Aliases:     config.NormalizeList(strings.Split(value(formAliases), ",")),
+		Description: value(formDescription),
+	}
+}
+
+// buildItems converts the configured projects into list items for the
+// projects and categories views
+func buildItems(cfg config.Config, sortOrder string) ([]list.Item, []list.Item) {
 	projectItems := []list.Item{}
 	categoryMap := make(map[string]int)
+	sessions := tmux.Sessions()
 
-	for _, p := range config.Projects {
-		cat := p.Category
-		if cat == "" {
-			cat = "Uncategorized"
-		}
-		projectItems = append(projectItems, ProjectItem{Name: p.Name, Path: p.Path, Category: cat})
+	for _, p := range cfg.Projects {
+		item := newProjectItem(p)
+		item.Tmux = sessions[tmux.SessionName(p.Name)]
+		projectItems = append(projectItems, item)
 
 		// Count projects per category
-		categoryMap[cat]++
+		categoryMap[item.Category]++
 	}
 
-	// Sort projects alphabetically by default (ascending)
-	sort.Slice(projectItems, func(i, j int) bool {
-		return projectItems[i].(ProjectItem).Name < projectItems[j].(ProjectItem).Name
-	})
+	sortProjectItems(projectItems, sortOrder)
 
 	// Create category items
 	categoryItems := []list.Item{}
@@ -255,6 +385,139 @@ func InitialModel() ListModel {
 		return categoryItems[i].(CategoryItem).Name < categoryItems[j].(CategoryItem).Name
 	})
 
+	return projectItems, categoryItems
+}
+
+// sortProjectItems sorts project items by name in the given order, or by
+// frecency score for "used"
+func sortProjectItems(items []list.Item, sortOrder string) {
+	switch sortOrder {
+	case "desc":
+		sort.Slice(items, func(i, j int) bool {
+			return items[i].(ProjectItem).Name > items[j].(ProjectItem).Name
+		})
+	case "used":
+		sort.Slice(items, func(i, j int) bool {
+			a, b := items[i].(ProjectItem), items[j].(ProjectItem)
+			if a.Score != b.Score {
+				return a.Score > b.Score
+			}
+			return a.Name < b.Name
+		})
+	default:
+		sort.Slice(items, func(i, j int) bool {
+			return items[i].(ProjectItem).Name < items[j].(ProjectItem).Name
+		})
+	}
+
+	nestWorktrees(items)
+}
+
+// nestWorktrees moves worktree projects right below their parent, in the
+// current order. Worktrees whose parent is not listed stay where they are.
+func nestWorktrees(items []list.Item) {
+	listed := make(map[string]bool)
+	for _, item := range items {
+		listed[item.(ProjectItem).Name] = true
+	}
+
+	var roots []ProjectItem
+	children := make(map[string][]ProjectItem)
+	for _, item := range items {
+		p := item.(ProjectItem)
+		parent := p.Project.Parent
+		p.Nested = parent != "" && parent != p.Name && listed[parent]
+		if p.Nested {
+			children[parent] = append(children[parent], p)
+		} else {
+			roots = append(roots, p)
+		}
+	}
+
+	nested := make([]list.Item, 0, len(items))
+	added := make(map[string]bool)
+	var add func(p ProjectItem)
+	add = func(p ProjectItem) {
+		added[p.Name] = true
+		nested = append(nested, p)
+		for _, child := range children[p.Name] {
+			if !added[child.Name] {
+				add(child)
+			}
+		}
+	}
+	for _, p := range roots {
+		add(p)
+	}
+
+	// Only a hand-edited config can make projects each other's parents;
+	// list them at the end rather than losing them
+	for _, item := range items {
+		if p := item.(ProjectItem); !added[p.Name] {
+			p.Nested = false
+			nested = append(nested, p)
+		}
+	}
+
+	copy(items, nested)
+}
+
+// nextSortOrder returns the sort order that follows current when cycling
+func nextSortOrder(current string) string {
+	switch current {
+	case "asc":
+		return "desc"
+	case "desc":
+		return "used"
+	default:
+		return "asc"
+	}
+}
+
+// projectNames maps the canonical path of every project to its name
+func (m ListModel) projectNames() map[string]string {
+	names := make(map[string]string)
+	for _, item := range m.ProjectItems {
+		if p, ok := item.(ProjectItem); ok {
+			names[fs.CanonicalPath(p.Path)] = p.Name
+		}
+	}
+	return names
+}
+
+// reloadProjects reloads the config from the store and rebuilds the list items
+func (m *ListModel) reloadProjects() error {
+	cfg, err := m.Store.Load()
+	if err != nil {
+		return err
+	}
+
+	m.ProjectItems, m.CategoryItems = buildItems(cfg, m.SortOrder)
+	m.List.SetItems(m.ProjectItems)
+	m.CategoryLaunchers = cfg.CategoryLaunchers
+	return nil
+}
+
+// InitialModel initializes the list model for the main view
+func InitialModel(store *config.Store) (ListModel, error) {
+	// Load projects
+	cfg, err := store.Load()
+	if err != nil {
+		return ListModel{}, err
+	}
+
+	launchers, err := launch.Registry(cfg)
+	if err != nil {
+		return ListModel{}, err
+	}
+	installed := make(map[string]bool)
+	for _, l := range launchers {
+		installed[l.Name] = l.Installed()
+	}
+
+	// Sort projects alphabetically by default (ascending)
+	projectItems, categoryItems := buildItems(cfg, "asc")
+
 	// Set up list with project items initially
 	l := list.New(projectItems, list.NewDefaultDelegate(), 0, 0)
 	l.Title = "(MPM) - Projects"
@@ -269,22 +532,26 @@ func InitialModel() ListModel {
 
 	// Return the model
 	return ListModel{
-		List:           l,
-		Keys:           keys,
-		ShowActions:    false,
-		ShowForm:       false,
-		FormInputs:     InitForm(),
-		FormFocused:    0,
-		FileChart:      []fs.FileEntry{},
-		FileTypeCounts: []fs.FileTypeCount{},
-		ViewMode:       "projects",
-		ProjectItems:   projectItems,
-		CategoryItems:  categoryItems,
-		SortOrder:      "asc", // Default sort order is ascending
-		ScrollOffset:   0,     // Initialize scroll position to 0
-		WindowWidth:    0,     // Will be set when tea.WindowSizeMsg is received
-		WindowHeight:   0,     // Will be set when tea.WindowSizeMsg is received
-	}
+		Store:             store,
+		List:              l,
+		Keys:              keys,
+		ShowActions:       false,
+		ShowForm:          false,
+		FormInputs:        InitForm(),
+		FormFocused:       0,
+		FileChart:         []fs.FileEntry{},
+		FileTypeCounts:    []fs.FileTypeCount{},
+		ViewMode:          "projects",
+		ProjectItems:      projectItems,
+		CategoryItems:     categoryItems,
+		SortOrder:         "asc", // Default sort order is ascending
+		ScrollOffset:      0,     // Initialize scroll position to 0
+		WindowWidth:       0,     // Will be set when tea.WindowSizeMsg is received
+		WindowHeight:      0,     // Will be set when tea.WindowSizeMsg is received
+		Launchers:         launchers,
+		Installed:         installed,
+		CategoryLaunchers: cfg.CategoryLaunchers,
+	}, nil
 }
 
 // HeaderView returns the header view
@@ -294,6 +561,10 @@ func (m ListModel) HeaderView() string {
 
 // FooterView returns the footer view
 func (m ListModel) FooterView() string {
+	if m.ErrorMessage != "" {
+		return ErrorStyle.Render("Error: " + m.ErrorMessage)
+	}
+
 	var info string
 	if m.ViewMode == "categories" {
 		info = HelpStyle.Render("Press 'q' to quit, 'tab' to switch to projects view, '/' to filter, 'enter' to view projects in category")
@@ -301,10 +572,13 @@ func (m ListModel) FooterView() string {
 		// Show sort order in projects view
 		sortStatus := ""
 		sortStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#7D56F4"))
-		if m.SortOrder == "asc" {
+		switch m.SortOrder {
+		case "asc":
 			sortStatus = sortStyle.Render("[A→Z]")
-		} else {
+		case "desc":
 			sortStatus = sortStyle.Render("[Z→A]")
+		default:
+			sortStatus = sortStyle.Render("[Most used]")
 		}
 
 		// Highlight the sort key
@@ -312,17 +586,21 @@ func (m ListModel) FooterView() string {
 		highlightedSort := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#7D56F4")).Render("'s'")
 		sortInstruction = highlightedSort + " to sort"
 
-		info = HelpStyle.Render(fmt.Sprintf("Press 'q' to quit, 'a' to add, %s %s, '/' to filter, 'tab' to switch to categories view",
+		info = HelpStyle.Render(fmt.Sprintf("Press 'q' to quit, 'a' to add, 'e' to edit, %s %s, '/' to filter, 'tab' to switch to categories view",
 			sortInstruction, sortStatus))
 	}
 	return info
 }
 
-// FormView returns the form view for adding new projects
+// FormView returns the form view for adding or editing projects
 func (m ListModel) FormView() string {
 	var b strings.Builder
 
-	b.WriteString("\n  Add New Project\n\n")
+	if m.EditingName != "" {
+		b.WriteString(fmt.Sprintf("\n  Edit Project '%s'\n\n", m.EditingName))
+	} else {
+		b.WriteString("\n  Add New Project\n\n")
+	}
 
 	for i := range m.FormInputs {
 		b.WriteString(m.FormInputs[i].View())
@@ -331,6 +609,10 @@ func (m ListModel) FormView() string {
 
 	b.WriteString("\nPress Enter to submit each field • ESC to cancel\n")
 
+	if m.ErrorMessage != "" {
+		b.WriteString("\n" + ErrorStyle.Render("Error: "+m.ErrorMessage) + "\n")
+	}
+
 	return b.String()
 }
 
@@ -346,10 +628,32 @@ func (m ListModel) ActionView() string {
 	category := lipgloss.NewStyle().Foreground(lipgloss.Color("#A8CC8C")).Render("[" + m.SelectedItem.Category + "]")
 	path := lipgloss.NewStyle().Foreground(lipgloss.Color("#B2B2B2")).Render(m.SelectedItem.Path)
 
-	b.WriteString(fmt.Sprintf("\n  %s %s\n  %s\n\n", title, category, path))
+	b.WriteString(fmt.Sprintf("\n  %s %s\n  %s\n", title, category, path))
+	if len(m.SelectedItem.Tags) > 0 {
+		tags := make([]string, len(m.SelectedItem.Tags))
+		for i, t := range m.SelectedItem.Tags {
+			tags[i] = TagStyle.Render("#" + t)
+		}
+		b.WriteString("  " + strings.Join(tags, " ") + "\n")
+	}
+	if len(m.SelectedItem.Aliases) > 0 {
+		b.WriteString("  Aliases: " + strings.Join(m.SelectedItem.Aliases, ", ") + "\n")
+	}
+	if m.SelectedItem.Desc != "" {
+		b.WriteString("  " + m.SelectedItem.Desc + "\n")
+	}
+	b.WriteString("\n")
+
+	if m.SelectedItem.Missing {
+		b.WriteString(ErrorStyle.Render("  ⚠ This directory no longer exists. Run 'mpm doctor --fix' to prune or relocate it.") + "\n\n")
+	}
+	if m.ErrorMessage != "" {
+		b.WriteString(ErrorStyle.Render("  Error: "+m.ErrorMessage) + "\n\n")
+	}
 
 	// Add Git information
 	b.WriteString(fs.RenderGitInfo(m.GitInfo))
+	b.WriteString(fs.RenderWorktrees(m.GitInfo, m.WorktreeNames))
 	b.WriteString("\n")
 
 	// Add file type statistics if we have data
@@ -358,16 +662,48 @@ func (m ListModel) ActionView() string {
 		b.WriteString("\n")
 	}
 
+	if m.PreferredErr != "" {
+		b.WriteString(HelpStyle.Render("  [enter] Open in preferred editor ("+m.PreferredErr+")") + "\n")
+	} else {
+		b.WriteString(fmt.Sprintf("  [enter] Open in %s\n", m.Preferred.Label))
+	}
 	b.WriteString("  [g] Go to directory\n")
-	b.WriteString("  [v] Open in VS Code\n")
-	b.WriteString("  [z] Open in Zed\n")
-	b.WriteString("  [c] Open in Cursor\n")
-	b.WriteString("  [n] Open in Neovim\n")
-	b.WriteString("  [f] Open in Finder/File Explorer\n")
-	b.WriteString("  [s] Open in Sublime Text\n")
-	b.WriteString("  [t] Open in Trae\n")
-	b.WriteString("  [m] Open in TextMate\n")
+	actionKeys := NewActionKeyMap(m.Launchers, m.Tasks)
+	for i, binding := range actionKeys.Launchers {
+		if !binding.Enabled() {
+			continue
+		}
+
+		line := fmt.Sprintf("  [%s] %s", binding.Help().Key, strings.ToUpper(binding.Help().Desc[:1])+binding.Help().Desc[1:])
+		if !m.Installed[m.Launchers[i].Name] {
+			// Still listed so the key is discoverable, but greyed out
+			line = HelpStyle.Render(line + " (not installed)")
+		}
+		b.WriteString(line + "\n")
+	}
+	switch {
+	case m.SelectedItem.Tmux:
+		b.WriteString("  [T] Attach to tmux session (running)\n")
+	case tmux.Available():
+		b.WriteString("  [T] Open tmux session\n")
+	default:
+		b.WriteString(HelpStyle.Render("  [T] Open tmux session (not installed)") + "\n")
+	}
+	b.WriteString("  [e] Edit project\n")
 	b.WriteString("  [d] Delete project\n")
+
+	if m.TasksErr != "" {
+		b.WriteString("\n" + ErrorStyle.Render("  Tasks: "+m.TasksErr) + "\n")
+	} else if len(m.Tasks) > 0 {
+		b.WriteString("\n  Tasks:\n")
+		for i, binding := range actionKeys.Tasks {
+			t := m.Tasks[i]
+			b.WriteString(fmt.Sprintf("  [%s] Run %s %s\n", binding.Help().Key, t.Name, HelpStyle.Render("("+t.Command+")")))
+		}
+		if extra := len(m.Tasks) - len(actionKeys.Tasks); extra > 0 {
+			b.WriteString(HelpStyle.Render(fmt.Sprintf("  %d more, see 'mpm run %s'", extra, m.SelectedItem.Name)) + "\n")
+		}
+	}
 	b.WriteString("\n  [ESC/q] Back to list\n")
 
 	// Add scroll hint
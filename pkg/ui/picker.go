@@ -0,0 +1,121 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// pickerHeight is the maximum number of choices shown at once
+const pickerHeight = 10
+
+// PickerItem is a single choice in the inline picker
+type PickerItem struct {
+	Title  string
+	Detail string
+}
+
+// PickerModel is a small inline list used to disambiguate between choices
+// without taking over the whole screen
+type PickerModel struct {
+	Prompt string
+	Items  []PickerItem
+	Cursor int
+	Chosen int // Index of the chosen item, -1 if cancelled
+	Done   bool
+}
+
+// Init initializes the picker
+func (m PickerModel) Init() tea.Cmd {
+	return nil
+}
+
+// Update handles keyboard navigation in the picker
+func (m PickerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	switch keyMsg.String() {
+	case "up", "k", "shift+tab":
+		if m.Cursor > 0 {
+			m.Cursor--
+		}
+	case "down", "j", "tab":
+		if m.Cursor < len(m.Items)-1 {
+			m.Cursor++
+		}
+	case "enter":
+		m.Chosen = m.Cursor
+		m.Done = true
+		return m, tea.Quit
+	case "esc", "q", "ctrl+c":
+		m.Done = true
+		return m, tea.Quit
+	default:
+		// Number keys jump straight to one of the first nine choices
+		if n, err := strconv.Atoi(keyMsg.String()); err == nil && n >= 1 && n <= len(m.Items) && n <= 9 {
+			m.Chosen = n - 1
+			m.Done = true
+			return m, tea.Quit
+		}
+	}
+
+	return m, nil
+}
+
+// View renders the visible window of choices
+func (m PickerModel) View() string {
+	if m.Done {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString(TitleStyle.Render(m.Prompt) + "\n")
+
+	// Keep the cursor inside the visible window
+	start := 0
+	if m.Cursor >= pickerHeight {
+		start = m.Cursor - pickerHeight + 1
+	}
+	end := start + pickerHeight
+	if end > len(m.Items) {
+		end = len(m.Items)
+	}
+
+	for i := start; i < end; i++ {
+		item := m.Items[i]
+		line := fmt.Sprintf("%d. %s %s", i+1, item.Title, PathStyle.Render(item.Detail))
+		if i == m.Cursor {
+			b.WriteString(SelectedItemStyle.Render("> "+line) + "\n")
+		} else {
+			b.WriteString(ItemStyle.Render(line) + "\n")
+		}
+	}
+
+	b.WriteString(HelpStyle.Render("  ↑/↓ to move • enter or 1-9 to choose • esc to cancel"))
+	return b.String()
+}
+
+// RunPicker shows an inline picker on the terminal and returns the index
+// of the chosen item, or -1 if the user cancelled. The picker draws on
+// stderr so stdout stays free for the shell wrapper.
+func RunPicker(prompt string, items []PickerItem) (int, error) {
+	model := PickerModel{Prompt: " " + prompt + " ", Items: items, Chosen: -1}
+
+	useStderr()
+	p := tea.NewProgram(model, tea.WithOutput(os.Stderr), tea.WithInputTTY())
+	result, err := p.Run()
+	if err != nil {
+		return -1, fmt.Errorf("running picker: %w", err)
+	}
+
+	if m, ok := result.(PickerModel); ok {
+		return m.Chosen, nil
+	}
+	return -1, nil
+}
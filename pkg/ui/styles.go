@@ -1,7 +1,10 @@
 package ui
 
 import (
+	"os"
+
 	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
 )
 
 // Styling constants for the UI
@@ -18,9 +21,15 @@ var (
 	// HelpStyle for help text
 	HelpStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#626262"))
 
+	// ErrorStyle for error messages
+	ErrorStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#FF5555")).Bold(true)
+
 	// CategoryStyle for project categories
 	CategoryStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#A8CC8C"))
 
+	// TagStyle for project tags
+	TagStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#FFB86C"))
+
 	// PathStyle for project paths
 	PathStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#B2B2B2"))
 
@@ -39,3 +48,9 @@ var (
 	// GitRemoteStyle for Git remote information
 	GitRemoteStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#7D56F4"))
 )
+
+// useStderr makes lipgloss detect color support on stderr, where the
+// interactive views are drawn, instead of on a possibly captured stdout
+func useStderr() {
+	lipgloss.DefaultRenderer().SetOutput(termenv.NewOutput(os.Stderr))
+}